@@ -0,0 +1,121 @@
+package retry
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MinAdaptiveRate is the lowest rate, in retries per second, to which an
+// Adaptive slows retries.
+const MinAdaptiveRate = 0.5
+
+// Adaptive throttles retries across many loops in response to throttling
+// signals from a dependency, in the spirit of the adaptive retry mode of the
+// AWS SDKs. It maintains a rate of retries per second shared by every loop
+// using it: each throttling error halves the rate, down to MinAdaptiveRate,
+// and each success raises it by one retry per second, up to its maximum.
+//
+// Loops use an Adaptive by wrapping their Limiter, Timer and Worker with its
+// Limiter, Timer and Worker methods. The Timer paces retries so that
+// together they do not exceed the current rate, and the Limiter suppresses
+// retries of throttling errors altogether while the rate is at its minimum.
+// It is safe for concurrent use.
+type Adaptive struct {
+	mu        sync.Mutex
+	throttled func(error) bool
+	max       float64
+	rate      float64
+	next      time.Time
+}
+
+// NewAdaptive returns an Adaptive that allows up to max retries per second
+// and treats errors for which throttled returns true as throttling signals.
+func NewAdaptive(throttled func(error) bool, max float64) *Adaptive {
+	if max < MinAdaptiveRate {
+		max = MinAdaptiveRate
+	}
+	return &Adaptive{
+		throttled: throttled,
+		max:       max,
+		rate:      max,
+	}
+}
+
+// Rate returns the current rate, in retries per second.
+func (a *Adaptive) Rate() float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.rate
+}
+
+// Limiter returns a Limiter that wraps another Limiter, recording throttling
+// errors and terminating the loop on a throttling error while the rate is at
+// its minimum. Otherwise the interior Limiter is evaluated.
+func (a *Adaptive) Limiter(limiter Limiter) Limiter {
+	return func(err error) bool {
+		if a.throttled(err) && !a.slow() {
+			return false
+		}
+		return limiter(err)
+	}
+}
+
+// Timer returns a Timer that calls the interior Timer and then waits until a
+// retry is permitted by the current rate. The wait may be canceled using a
+// context.
+func (a *Adaptive) Timer(ctx context.Context, timer Timer) Timer {
+	return func() {
+		timer()
+		sleepContext(ctx, a.reserve())
+	}
+}
+
+// Worker returns a Worker that records the successes of worker.
+func (a *Adaptive) Worker(worker Worker) Worker {
+	return func() error {
+		err := worker()
+		if err == nil {
+			a.speed()
+		}
+		return err
+	}
+}
+
+// slow halves the rate, reporting false if it was already at its minimum.
+func (a *Adaptive) slow() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.rate <= MinAdaptiveRate {
+		return false
+	}
+	a.rate /= 2
+	if a.rate < MinAdaptiveRate {
+		a.rate = MinAdaptiveRate
+	}
+	return true
+}
+
+// speed raises the rate after a success.
+func (a *Adaptive) speed() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.rate++
+	if a.rate > a.max {
+		a.rate = a.max
+	}
+}
+
+// reserve claims the next retry permitted by the rate and returns how long
+// the caller must wait for it.
+func (a *Adaptive) reserve() time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	t := now()
+	if a.next.Before(t) {
+		a.next = t
+	}
+	wait := a.next.Sub(t)
+	a.next = a.next.Add(time.Duration(float64(time.Second) / a.rate))
+	return wait
+}
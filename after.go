@@ -0,0 +1,123 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// RetryAfterError is an error carrying the delay before the next attempt, as
+// specified by the server that produced it, for example in an HTTP
+// Retry-After header. Any error with a RetryAfter method is treated the same
+// way; RetryAfterError is a convenient implementation.
+//
+// When an attempt fails with such an error and the Limiter allows a further
+// attempt, the loop sleeps for the specified delay in place of the Timer's.
+// The Timers a loop constructs from a TimerPolicy, as a Retryer, RetryPolicy
+// and Supervise do, are called as usual and those of this package sleep for
+// the specified delay in place of their own, so that their backoff advances;
+// should such a Timer sleep for less, the loop sleeps for the remainder. The
+// Timer of Retry, RetryContext and the other loops given a Timer constructed
+// by the caller cannot be told of the delay, so the loop sleeps for it
+// without calling the Timer.
+type RetryAfterError struct {
+	Err   error
+	Delay time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter returns the delay before the next attempt.
+func (e *RetryAfterError) RetryAfter() time.Duration {
+	return e.Delay
+}
+
+// RetryAfter returns the delay specified by the first error in err's chain
+// that has a RetryAfter method. It reports false if there is no such error.
+func RetryAfter(err error) (time.Duration, bool) {
+	if ra, ok := find[interface{ RetryAfter() time.Duration }](err); ok {
+		return ra.RetryAfter(), true
+	}
+	return 0, false
+}
+
+// timerStateKey is the context key of the timerState of a loop.
+type timerStateKey struct{}
+
+// timerState is shared by a loop with the Timers it constructs from a
+// TimerPolicy, through the context passed to the policy. While the Timer is
+// running it holds the delay specified by the error of the failed attempt
+// and a function through which the Timer announces the delay it is about to
+// sleep for.
+type timerState struct {
+	retryAfter time.Duration
+	ok         bool
+	announce   func(time.Duration)
+}
+
+// withTimerState returns a copy of ctx carrying a new timerState.
+func withTimerState(ctx context.Context) (context.Context, *timerState) {
+	s := &timerState{}
+	return context.WithValue(ctx, timerStateKey{}, s), s
+}
+
+// timerStateFrom returns the timerState carried by ctx, or nil.
+func timerStateFrom(ctx context.Context) *timerState {
+	s, _ := ctx.Value(timerStateKey{}).(*timerState)
+	return s
+}
+
+// begin records the delay specified by err, if any, and the announce
+// function, which may be nil, before the Timer is called.
+func (s *timerState) begin(err error, announce func(time.Duration)) {
+	if s != nil {
+		s.retryAfter, s.ok = RetryAfter(err)
+		s.announce = announce
+	}
+}
+
+// end clears the state once the Timer has returned.
+func (s *timerState) end() {
+	if s != nil {
+		*s = timerState{}
+	}
+}
+
+// take returns the delay specified by the error of the failed attempt, if
+// any, so that the Timer can sleep for it. Only the first Timer to take the
+// delay sleeps for it, should a Timer be composed of several.
+func (s *timerState) take() (time.Duration, bool) {
+	if s == nil || !s.ok {
+		return 0, false
+	}
+	s.ok = false
+	return s.retryAfter, true
+}
+
+// remainder sleeps for the part of the delay specified by the error of the
+// failed attempt that a Timer which slept for slept did not take, canceled by
+// ctx.
+func (s *timerState) remainder(ctx context.Context, slept time.Duration) {
+	if after, ok := s.take(); ok && after > slept {
+		sleepContext(ctx, after-slept)
+	}
+}
+
+// delay returns the delay a Timer that computed d should sleep for, and
+// announces it.
+func (s *timerState) delay(d time.Duration) time.Duration {
+	if after, ok := s.take(); ok {
+		d = after
+	}
+	if s != nil && s.announce != nil {
+		announce := s.announce
+		s.announce = nil
+		announce(d)
+	}
+	return d
+}
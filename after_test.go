@@ -0,0 +1,107 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/colvin/retry"
+	"github.com/colvin/retry/retrytest"
+)
+
+func TestRetryAfterHonoredByPolicyTimers(t *testing.T) {
+	clock := retrytest.NewClock(time.Now())
+	defer clock.Install()()
+
+	var attempts int
+	err := retry.New(
+		retry.WithMaxAttempts(3),
+		retry.WithBackoff(time.Second, time.Minute),
+	).Do(func() error {
+		attempts++
+		if attempts == 1 {
+			return &retry.RetryAfterError{Err: errors.New("busy"), Delay: 5 * time.Second}
+		}
+		return errors.New("fail")
+	})
+	if err == nil {
+		t.Fatal("err = nil, want an error")
+	}
+	// The backoff advances while the server's delay is honored.
+	want := []time.Duration{5 * time.Second, 2 * time.Second}
+	if got := clock.Sleeps(); !reflect.DeepEqual(got, want) {
+		t.Errorf("sleeps = %v, want %v", got, want)
+	}
+}
+
+func TestRetryAfterHonoredByCallerTimers(t *testing.T) {
+	busy := func() error {
+		return &retry.RetryAfterError{Err: errors.New("busy"), Delay: 300 * time.Millisecond}
+	}
+	tests := []struct {
+		name string
+		loop func()
+	}{
+		{
+			name: "Retry",
+			loop: func() {
+				retry.Retry(busy, retry.Counts(3), retry.MultiplicativeBackoff(time.Second, time.Minute))
+			},
+		},
+		{
+			name: "RetryContext",
+			loop: func() {
+				retry.RetryContext(context.Background(), func(context.Context) error {
+					return busy()
+				}, retry.Counts(3), retry.MultiplicativeBackoff(time.Second, time.Minute))
+			},
+		},
+		{
+			name: "Fixed.Do",
+			loop: func() {
+				retry.Fixed{Attempts: 3, Delay: time.Second}.Do(busy)
+			},
+		},
+		{
+			name: "Fixed.DoContext",
+			loop: func() {
+				retry.Fixed{Attempts: 3, Delay: time.Second}.DoContext(context.Background(), func(context.Context) error {
+					return busy()
+				})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := retrytest.NewClock(time.Now())
+			defer clock.Install()()
+
+			tt.loop()
+			want := []time.Duration{300 * time.Millisecond, 300 * time.Millisecond}
+			if got := clock.Sleeps(); !reflect.DeepEqual(got, want) {
+				t.Errorf("sleeps = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRetryAfterRemainderOfPolicyTimer(t *testing.T) {
+	clock := retrytest.NewClock(time.Now())
+	defer clock.Install()()
+
+	retry.New(
+		retry.WithMaxAttempts(2),
+		retry.WithTimer(func(context.Context) retry.Timer {
+			return retry.MultiplicativeBackoff(time.Second, time.Minute)
+		}),
+	).Do(func() error {
+		return &retry.RetryAfterError{Err: errors.New("busy"), Delay: 5 * time.Second}
+	})
+	// The Timer ignores the delay, so the loop sleeps for the rest of it.
+	want := []time.Duration{time.Second, 4 * time.Second}
+	if got := clock.Sleeps(); !reflect.DeepEqual(got, want) {
+		t.Errorf("sleeps = %v, want %v", got, want)
+	}
+}
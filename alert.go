@@ -0,0 +1,24 @@
+package retry
+
+// RetryAlerting is the same as Retry but reports the loop to onAlert if more
+// than threshold attempts were made, whether or not the loop eventually
+// succeeded. Loops that recover within the threshold are not reported. The
+// alert is passed the number of attempts made and the error returned by each
+// failed attempt, in order. onAlert is called at most once per loop, after
+// the loop has finished.
+func RetryAlerting(threshold int, onAlert func(int, []error), worker Worker, limiter Limiter, timer Timer) error {
+	var attempts int
+	var errs []error
+	err := Retry(func() error {
+		attempts++
+		err := worker()
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return err
+	}, limiter, timer)
+	if attempts > threshold {
+		onAlert(attempts, errs)
+	}
+	return err
+}
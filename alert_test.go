@@ -0,0 +1,52 @@
+package retry
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRetryAlerting(t *testing.T) {
+	errFail := errors.New("fail")
+	tests := []struct {
+		name      string
+		failures  int
+		threshold int
+		alerts    int
+	}{
+		{"below threshold", 1, 3, 0},
+		{"at threshold", 2, 3, 0},
+		{"above threshold", 3, 3, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var failures, alerts, alertAttempts int
+			var alertErrs []error
+			err := RetryAlerting(tt.threshold, func(attempts int, errs []error) {
+				alerts++
+				alertAttempts = attempts
+				alertErrs = errs
+			}, func() error {
+				if failures < tt.failures {
+					failures++
+					return errFail
+				}
+				return nil
+			}, Forever(), func() {})
+			if err != nil {
+				t.Fatalf("err = %v, want nil", err)
+			}
+			if alerts != tt.alerts {
+				t.Fatalf("alerts = %d, want %d", alerts, tt.alerts)
+			}
+			if alerts == 0 {
+				return
+			}
+			if alertAttempts != tt.failures+1 {
+				t.Errorf("alert attempts = %d, want %d", alertAttempts, tt.failures+1)
+			}
+			if len(alertErrs) != tt.failures {
+				t.Errorf("alert errors = %v, want %d errors", alertErrs, tt.failures)
+			}
+		})
+	}
+}
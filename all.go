@@ -0,0 +1,45 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+)
+
+// AttemptError is an error returned by a particular attempt of the Worker.
+type AttemptError struct {
+	// Attempt is the number of the attempt, starting at one.
+	Attempt int
+	// Err is the error returned by the attempt.
+	Err error
+}
+
+func (e *AttemptError) Error() string {
+	return fmt.Sprintf("attempt %d: %v", e.Attempt, e.Err)
+}
+
+func (e *AttemptError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAll is the same as Retry but if the loop terminates without success
+// the errors from every attempt are returned, joined using errors.Join. Each
+// of the joined errors is an *AttemptError recording the attempt it came
+// from. As with Retry, the mark of a permanent error is removed, so that the
+// joined error does not terminate an enclosing loop.
+func RetryAll(worker Worker, limiter Limiter, timer Timer) error {
+	var attempts int
+	var errs []error
+	err := Retry(func() error {
+		attempts++
+		err := worker()
+		if err != nil {
+			recorded, _ := unwrapPermanent(err)
+			errs = append(errs, &AttemptError{Attempt: attempts, Err: recorded})
+		}
+		return err
+	}, limiter, timer)
+	if err == nil {
+		return nil
+	}
+	return errors.Join(errs...)
+}
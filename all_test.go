@@ -0,0 +1,37 @@
+package retry_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/colvin/retry"
+)
+
+func TestRetryAll(t *testing.T) {
+	errFirst, errLast := errors.New("first"), errors.New("last")
+	var attempts int
+	err := retry.RetryAll(func() error {
+		attempts++
+		if attempts == 1 {
+			return errFirst
+		}
+		return retry.Permanent(errLast)
+	}, retry.Forever(), func() {})
+
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+	if !errors.Is(err, errFirst) || !errors.Is(err, errLast) {
+		t.Errorf("err = %v, want both attempts' errors", err)
+	}
+	if retry.IsPermanent(err) {
+		t.Errorf("err = %v is still marked permanent", err)
+	}
+	var ae *retry.AttemptError
+	if !errors.As(err, &ae) || ae.Attempt != 1 || ae.Err != errFirst {
+		t.Errorf("first AttemptError = %+v, want attempt 1 with %v", ae, errFirst)
+	}
+	if want := "attempt 1: first\nattempt 2: last"; err.Error() != want {
+		t.Errorf("err = %q, want %q", err, want)
+	}
+}
@@ -0,0 +1,88 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Attempt describes a completed attempt of the Worker, typically one that
+// failed.
+type Attempt struct {
+	// Number is the number of the attempt, starting at one.
+	Number int
+	// Start is the time at which the first attempt of the loop was made.
+	Start time.Time
+	// Elapsed is the time between Start and the end of this attempt.
+	Elapsed time.Duration
+	// Duration is the time taken by this attempt alone.
+	Duration time.Duration
+	// Delay is the wait that preceded this attempt, zero for the first.
+	Delay time.Duration
+	// Err is the error returned by the attempt, if any.
+	Err error
+}
+
+// LimiterFunc is a Limiter that is passed a description of the failed attempt
+// rather than only its error.
+type LimiterFunc func(Attempt) bool
+
+// TimerFunc is a Timer that is passed a description of the failed attempt.
+type TimerFunc func(Attempt)
+
+// AttemptLimiter adapts a Limiter for use where a LimiterFunc is expected.
+func AttemptLimiter(limiter Limiter) LimiterFunc {
+	return func(a Attempt) bool {
+		return limiter(a.Err)
+	}
+}
+
+// AttemptTimer adapts a Timer for use where a TimerFunc is expected.
+func AttemptTimer(timer Timer) TimerFunc {
+	return func(_ Attempt) {
+		timer()
+	}
+}
+
+// DelayTimer returns a TimerFunc that sleeps for the duration computed by fn
+// from the number of the failed attempt and its error, so that arbitrary
+// schedules can be expressed without managing the sleep.
+func DelayTimer(fn func(attempt int, err error) time.Duration) TimerFunc {
+	return func(a Attempt) {
+		sleep(fn(a.Number, a.Err))
+	}
+}
+
+// CancelableDelayTimer is the same as DelayTimer but can be canceled using a
+// context.
+func CancelableDelayTimer(ctx context.Context, fn func(attempt int, err error) time.Duration) TimerFunc {
+	return func(a Attempt) {
+		sleepContext(ctx, fn(a.Number, a.Err))
+	}
+}
+
+// RetryFunc is the same as Retry but uses a LimiterFunc and TimerFunc.
+func RetryFunc(worker Worker, limiter LimiterFunc, timer TimerFunc) error {
+	l := loop{limiter: limiter, timer: timer, contextFree: true}
+	return l.run(context.Background(), func(_ context.Context) error {
+		return worker()
+	})
+}
+
+// attemptKey is the context key of the attempt number.
+type attemptKey struct{}
+
+// AttemptFromContext returns the number of the attempt, starting at one,
+// whose context is ctx. The context-aware loops of this package, such as
+// RetryContext, Retryer.DoContext, Hedge and Supervise, record it in the
+// context passed to each attempt of the Worker, so that the Worker can, for
+// example, tag its requests with it. It reports false if ctx is not the
+// context of an attempt.
+func AttemptFromContext(ctx context.Context) (int, bool) {
+	n, ok := ctx.Value(attemptKey{}).(int)
+	return n, ok
+}
+
+// withAttempt returns a copy of ctx recording the given attempt number.
+func withAttempt(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, n)
+}
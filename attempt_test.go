@@ -0,0 +1,28 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/colvin/retry"
+)
+
+func TestAttemptFromContext(t *testing.T) {
+	var got []int
+	retry.New(retry.WithMaxAttempts(3)).DoContext(context.Background(), func(ctx context.Context) error {
+		n, ok := retry.AttemptFromContext(ctx)
+		if !ok {
+			t.Fatal("no attempt number in the context")
+		}
+		got = append(got, n)
+		return errors.New("fail")
+	})
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("attempts = %v, want %v", got, want)
+	}
+	if _, ok := retry.AttemptFromContext(context.Background()); ok {
+		t.Error("attempt number found outside a loop")
+	}
+}
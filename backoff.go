@@ -0,0 +1,112 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// ExponentialBackoff returns a Timer that sleeps for a duration, where the
+// duration is multiplied by factor each iteration until a ceiling is reached.
+// The factor should be greater than one. The Timer tracks the backoff of a
+// single loop and must not be shared; see ExponentialBackoffPolicy.
+func ExponentialBackoff(base time.Duration, factor float64, ceil time.Duration) Timer {
+	return sleeper(exponential(base, factor, ceil))
+}
+
+// CancelableExponentialBackoff is the same as ExponentialBackoff but can be
+// canceled using a context.
+func CancelableExponentialBackoff(ctx context.Context, base time.Duration, factor float64, ceil time.Duration) Timer {
+	return cancelableSleeper(ctx, exponential(base, factor, ceil))
+}
+
+// exponential returns a function computing successive exponential backoff
+// delays.
+func exponential(base time.Duration, factor float64, ceil time.Duration) func() time.Duration {
+	cur := base
+	return func() time.Duration {
+		dur := cur
+		if cur != ceil {
+			if next := float64(cur) * factor; next < float64(ceil) {
+				cur = time.Duration(next)
+			} else {
+				cur = ceil
+			}
+		}
+		return dur
+	}
+}
+
+// FibonacciBackoff returns a Timer that sleeps for a duration, where the
+// duration grows along the Fibonacci sequence (base, base, 2*base, 3*base,
+// 5*base, ...) each iteration until a ceiling is reached. The Timer tracks the
+// backoff of a single loop and must not be shared; see FibonacciBackoffPolicy.
+func FibonacciBackoff(base time.Duration, ceil time.Duration) Timer {
+	return sleeper(fibonacci(base, ceil))
+}
+
+// CancelableFibonacciBackoff is the same as FibonacciBackoff but can be
+// canceled using a context.
+func CancelableFibonacciBackoff(ctx context.Context, base time.Duration, ceil time.Duration) Timer {
+	return cancelableSleeper(ctx, fibonacci(base, ceil))
+}
+
+// fibonacci returns a function computing successive Fibonacci backoff delays.
+func fibonacci(base time.Duration, ceil time.Duration) func() time.Duration {
+	cur, next := base, base
+	return func() time.Duration {
+		dur := cur
+		if cur != ceil {
+			cur, next = next, cur+next
+			if cur > ceil {
+				cur = ceil
+			}
+		}
+		return dur
+	}
+}
+
+// LinearBackoff returns a Timer that sleeps for a duration, where the duration
+// grows by increment each iteration until a ceiling is reached. The Timer
+// tracks the backoff of a single loop and must not be shared; see
+// LinearBackoffPolicy.
+func LinearBackoff(base time.Duration, increment time.Duration, ceil time.Duration) Timer {
+	return sleeper(linear(base, increment, ceil))
+}
+
+// CancelableLinearBackoff is the same as LinearBackoff but can be canceled
+// using a context.
+func CancelableLinearBackoff(ctx context.Context, base time.Duration, increment time.Duration, ceil time.Duration) Timer {
+	return cancelableSleeper(ctx, linear(base, increment, ceil))
+}
+
+// linear returns a function computing successive linear backoff delays.
+func linear(base time.Duration, increment time.Duration, ceil time.Duration) func() time.Duration {
+	cur := base
+	return func() time.Duration {
+		dur := cur
+		if cur != ceil {
+			cur = cur + increment
+			if cur > ceil {
+				cur = ceil
+			}
+		}
+		return dur
+	}
+}
+
+// sleeper returns a Timer that sleeps for each successive duration computed
+// by next.
+func sleeper(next func() time.Duration) Timer {
+	return func() {
+		sleep(next())
+	}
+}
+
+// cancelableSleeper is the same as sleeper but can be canceled using a
+// context.
+func cancelableSleeper(ctx context.Context, next func() time.Duration) Timer {
+	state := timerStateFrom(ctx)
+	return func() {
+		sleepContext(ctx, state.delay(next()))
+	}
+}
@@ -0,0 +1,167 @@
+package retry
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrBreakerOpen is returned by a Worker wrapped by an open Breaker in place
+// of attempting the work.
+var ErrBreakerOpen = errors.New("retry: circuit breaker is open")
+
+// BreakerState is the state of a Breaker.
+type BreakerState int
+
+const (
+	// BreakerClosed allows every attempt.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails every attempt until its cool-down has passed.
+	BreakerOpen
+	// BreakerHalfOpen allows a single trial attempt, whose outcome closes or
+	// reopens the Breaker.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// Breaker is a circuit breaker that stops attempts against a dependency that
+// is failing. It opens once threshold consecutive attempts have failed, after
+// which attempts fail immediately with ErrBreakerOpen. Once the cool-down has
+// passed it becomes half-open and allows a single trial attempt: if the trial
+// succeeds the Breaker closes, otherwise it opens again. Only the outcomes of
+// attempts allowed since the Breaker last changed state are recorded, so that
+// an attempt that was already in flight when the Breaker opened cannot close
+// it again.
+//
+// Loops use a Breaker by wrapping their Worker with Breaker.Worker and,
+// optionally, their Limiter with Breaker.Limiter. A Breaker is typically
+// shared by every loop calling the same dependency. It is safe for concurrent
+// use.
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     BreakerState
+	failures  int
+	opened    time.Time
+	probing   bool
+	// generation is incremented on every change of state.
+	generation uint64
+}
+
+// NewBreaker returns a closed Breaker that opens after threshold consecutive
+// failures and stays open for cooldown.
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// State returns the current state of the Breaker.
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && since(b.opened) >= b.cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// Worker returns a Worker that calls worker only if the Breaker allows it,
+// and otherwise returns ErrBreakerOpen. The outcome of each call is recorded
+// by the Breaker.
+func (b *Breaker) Worker(worker Worker) Worker {
+	return func() error {
+		gen, ok := b.allow()
+		if !ok {
+			return ErrBreakerOpen
+		}
+		err := worker()
+		b.record(gen, err)
+		return err
+	}
+}
+
+// Limiter returns a Limiter that wraps another Limiter, terminating the loop
+// if the attempt failed with ErrBreakerOpen or the Breaker is open, rather
+// than waiting only for the next attempt to fail immediately.
+func (b *Breaker) Limiter(limiter Limiter) Limiter {
+	return func(err error) bool {
+		if errors.Is(err, ErrBreakerOpen) || b.State() == BreakerOpen {
+			return false
+		}
+		return limiter(err)
+	}
+}
+
+// allow reports whether an attempt may be made, moving an open Breaker whose
+// cool-down has passed to half-open, and returns the generation in which the
+// attempt is made.
+func (b *Breaker) allow() (uint64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerOpen:
+		if since(b.opened) < b.cooldown {
+			return 0, false
+		}
+		b.set(BreakerHalfOpen)
+		b.probing = false
+		fallthrough
+	case BreakerHalfOpen:
+		if b.probing {
+			return 0, false
+		}
+		b.probing = true
+	}
+	return b.generation, true
+}
+
+// record records the outcome of an attempt made in the given generation,
+// ignoring it if the Breaker has changed state since.
+func (b *Breaker) record(gen uint64, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if gen != b.generation {
+		return
+	}
+	switch {
+	case err == nil:
+		if b.state != BreakerClosed {
+			b.set(BreakerClosed)
+		}
+		b.failures = 0
+	case b.state == BreakerHalfOpen:
+		b.trip()
+	case b.state == BreakerClosed:
+		b.failures++
+		if b.failures >= b.threshold {
+			b.trip()
+		}
+	}
+	b.probing = false
+}
+
+func (b *Breaker) trip() {
+	b.set(BreakerOpen)
+	b.opened = now()
+	b.failures = 0
+}
+
+// set changes the state of the Breaker, starting a new generation.
+func (b *Breaker) set(state BreakerState) {
+	b.state = state
+	b.generation++
+}
@@ -0,0 +1,133 @@
+package retry_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/colvin/retry"
+	"github.com/colvin/retry/retrytest"
+)
+
+var (
+	errBreakerFail = errors.New("fail")
+	succeed        = func() error { return nil }
+	fail           = func() error { return errBreakerFail }
+)
+
+// trip opens b, whose threshold is threshold, by failing attempts.
+func trip(t *testing.T, b *retry.Breaker, threshold int) {
+	t.Helper()
+	for i := 0; i < threshold; i++ {
+		if err := b.Worker(fail)(); err != errBreakerFail {
+			t.Fatalf("attempt %d = %v, want %v", i+1, err, errBreakerFail)
+		}
+	}
+	if s := b.State(); s != retry.BreakerOpen {
+		t.Fatalf("state = %v, want %v", s, retry.BreakerOpen)
+	}
+}
+
+func TestBreakerOpensAfterThreshold(t *testing.T) {
+	clock := retrytest.NewClock(time.Now())
+	defer clock.Install()()
+
+	b := retry.NewBreaker(3, time.Minute)
+	b.Worker(fail)()
+	b.Worker(fail)()
+	b.Worker(succeed)()
+	b.Worker(fail)()
+	b.Worker(fail)()
+	if s := b.State(); s != retry.BreakerClosed {
+		t.Fatalf("state = %v, want %v after non-consecutive failures", s, retry.BreakerClosed)
+	}
+	b.Worker(fail)()
+	if s := b.State(); s != retry.BreakerOpen {
+		t.Fatalf("state = %v, want %v", s, retry.BreakerOpen)
+	}
+
+	var calls int
+	err := b.Worker(func() error {
+		calls++
+		return nil
+	})()
+	if err != retry.ErrBreakerOpen || calls != 0 {
+		t.Errorf("open Breaker returned %v after %d calls, want %v after none", err, calls, retry.ErrBreakerOpen)
+	}
+}
+
+func TestBreakerHalfOpenTrial(t *testing.T) {
+	tests := []struct {
+		name  string
+		trial func() error
+		want  retry.BreakerState
+	}{
+		{name: "success closes", trial: succeed, want: retry.BreakerClosed},
+		{name: "failure reopens", trial: fail, want: retry.BreakerOpen},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := retrytest.NewClock(time.Now())
+			defer clock.Install()()
+
+			b := retry.NewBreaker(2, time.Minute)
+			trip(t, b, 2)
+			clock.Advance(time.Minute)
+			if s := b.State(); s != retry.BreakerHalfOpen {
+				t.Fatalf("state = %v, want %v", s, retry.BreakerHalfOpen)
+			}
+			b.Worker(func() error {
+				// Only one trial is allowed at a time.
+				if err := b.Worker(succeed)(); err != retry.ErrBreakerOpen {
+					t.Errorf("concurrent trial = %v, want %v", err, retry.ErrBreakerOpen)
+				}
+				return tt.trial()
+			})()
+			if s := b.State(); s != tt.want {
+				t.Errorf("state = %v, want %v", s, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreakerIgnoresOutcomesFromEarlierStates(t *testing.T) {
+	clock := retrytest.NewClock(time.Now())
+	defer clock.Install()()
+
+	b := retry.NewBreaker(2, time.Minute)
+	// An attempt in flight while other attempts open the Breaker succeeds.
+	b.Worker(func() error {
+		trip(t, b, 2)
+		return nil
+	})()
+	if s := b.State(); s != retry.BreakerOpen {
+		t.Errorf("state = %v, want %v after a stale success", s, retry.BreakerOpen)
+	}
+
+	// A stale failure does not reopen a Breaker closed by its trial.
+	b = retry.NewBreaker(1, time.Minute)
+	b.Worker(func() error {
+		b.Worker(fail)()
+		clock.Advance(time.Minute)
+		b.Worker(succeed)()
+		return errBreakerFail
+	})()
+	if s := b.State(); s != retry.BreakerClosed {
+		t.Errorf("state = %v, want %v after a stale failure", s, retry.BreakerClosed)
+	}
+}
+
+func TestBreakerLimiter(t *testing.T) {
+	clock := retrytest.NewClock(time.Now())
+	defer clock.Install()()
+
+	b := retry.NewBreaker(2, time.Minute)
+	var calls int
+	err := retry.Retry(b.Worker(func() error {
+		calls++
+		return errBreakerFail
+	}), b.Limiter(retry.Forever()), func() {})
+	if err != errBreakerFail || calls != 2 {
+		t.Errorf("err = %v after %d calls, want %v after 2", err, calls, errBreakerFail)
+	}
+}
@@ -0,0 +1,70 @@
+package retry
+
+import "sync"
+
+// Budget limits the retries made by many loops, across goroutines, relative
+// to the number of successful attempts, so that widespread failure of a
+// dependency does not multiply the load on it. It is a token bucket: each
+// retry withdraws a token, each success deposits ratio tokens, and the bucket
+// holds at most reserve tokens, which it starts with. Over any period the
+// loops sharing a Budget therefore make at most ratio retries per success
+// plus reserve.
+//
+// Loops use a Budget by wrapping their Limiter with Budget.Limiter and their
+// Worker with Budget.Worker. It is safe for concurrent use.
+type Budget struct {
+	mu      sync.Mutex
+	ratio   float64
+	reserve float64
+	tokens  float64
+}
+
+// NewBudget returns a full Budget allowing ratio retries per success and
+// holding up to reserve retries.
+func NewBudget(ratio float64, reserve int) *Budget {
+	return &Budget{
+		ratio:   ratio,
+		reserve: float64(reserve),
+		tokens:  float64(reserve),
+	}
+}
+
+// Limiter returns a Limiter that wraps another Limiter, withdrawing a token
+// from the Budget whenever the interior Limiter allows a further attempt.
+// The loop is terminated if the Budget is empty.
+func (b *Budget) Limiter(limiter Limiter) Limiter {
+	return func(err error) bool {
+		return limiter(err) && b.withdraw()
+	}
+}
+
+// Worker returns a Worker that deposits into the Budget whenever worker
+// succeeds.
+func (b *Budget) Worker(worker Worker) Worker {
+	return func() error {
+		err := worker()
+		if err == nil {
+			b.deposit()
+		}
+		return err
+	}
+}
+
+func (b *Budget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (b *Budget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens += b.ratio
+	if b.tokens > b.reserve {
+		b.tokens = b.reserve
+	}
+}
@@ -0,0 +1,50 @@
+package retry
+
+import "context"
+
+// CanceledError is returned by a loop of a Retryer configured using
+// WithCancelError that terminated because its context was done. Both the
+// context's error and the error of the last attempt are in its chain, so
+// errors.Is(err, context.Canceled) distinguishes cancellation from giving up.
+type CanceledError struct {
+	// Cause is the context's error.
+	Cause error
+	// Err is the error of the last attempt, or nil if no attempt was made.
+	Err error
+}
+
+func (e *CanceledError) Error() string {
+	if e.Err == nil {
+		return e.Cause.Error()
+	}
+	return e.Cause.Error() + ": " + e.Err.Error()
+}
+
+// Unwrap returns Cause and, if an attempt was made, Err.
+func (e *CanceledError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Cause}
+	}
+	return []error{e.Cause, e.Err}
+}
+
+// WithCancelError returns a *CanceledError in place of the error of the last
+// attempt when a loop terminates because its context is done.
+func WithCancelError() Option {
+	return func(r *Retryer) {
+		r.cancelError = true
+	}
+}
+
+// canceled returns the error with which a loop over ctx that has expired
+// terminates after an attempt failing with err.
+func (l *loop) canceled(ctx context.Context, err error) error {
+	if !l.cancelError {
+		return err
+	}
+	cause := ctx.Err()
+	if cause == nil {
+		cause = context.DeadlineExceeded
+	}
+	return &CanceledError{Cause: cause, Err: err}
+}
@@ -0,0 +1,20 @@
+package retry
+
+// WithChaos returns a Worker that fails with chaosErr, without calling worker,
+// with probability failureRate. Otherwise the returned Worker calls worker.
+// Randomness is drawn from Rand. If failureRate is zero worker is returned
+// unchanged.
+//
+// WithChaos is intended for exercising retry configurations with controlled
+// fault injection in tests and staging environments, not for production use.
+func WithChaos(worker Worker, failureRate float64, chaosErr error) Worker {
+	if failureRate <= 0 {
+		return worker
+	}
+	return func() error {
+		if Rand() < failureRate {
+			return chaosErr
+		}
+		return worker()
+	}
+}
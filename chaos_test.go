@@ -0,0 +1,44 @@
+package retry
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithChaos(t *testing.T) {
+	defer func(orig func() float64) { Rand = orig }(Rand)
+	draws := []float64{0.1, 0.5, 0.29, 0.3, 0.9}
+	Rand = func() float64 {
+		v := draws[0]
+		draws = draws[1:]
+		return v
+	}
+
+	errChaos := errors.New("chaos")
+	var calls int
+	worker := WithChaos(func() error {
+		calls++
+		return nil
+	}, 0.3, errChaos)
+
+	want := []error{errChaos, nil, errChaos, nil, nil}
+	for i, w := range want {
+		if err := worker(); err != w {
+			t.Errorf("call %d: err = %v, want %v", i, err, w)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("worker called %d times, want 3", calls)
+	}
+}
+
+func TestWithChaosZeroRate(t *testing.T) {
+	defer func(orig func() float64) { Rand = orig }(Rand)
+	Rand = func() float64 {
+		t.Fatal("Rand called with a zero failure rate")
+		return 0
+	}
+	if err := WithChaos(func() error { return nil }, 0, errors.New("chaos"))(); err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+}
@@ -0,0 +1,39 @@
+package retry
+
+import "errors"
+
+// OnErrors returns a Limiter that allows a further attempt only if the error
+// matches one of the targets, as reported by errors.Is.
+func OnErrors(targets ...error) Limiter {
+	return func(err error) bool {
+		return isAny(err, targets)
+	}
+}
+
+// UnlessErrors returns a Limiter that terminates the loop if the error matches
+// one of the targets, as reported by errors.Is, and otherwise allows a further
+// attempt.
+func UnlessErrors(targets ...error) Limiter {
+	return func(err error) bool {
+		return !isAny(err, targets)
+	}
+}
+
+// OnErrorAs returns a Limiter that allows a further attempt only if an error
+// of type E is found in the error's chain, as reported by errors.As.
+func OnErrorAs[E error]() Limiter {
+	return func(err error) bool {
+		var target E
+		return errors.As(err, &target)
+	}
+}
+
+// isAny reports whether err matches any of the targets.
+func isAny(err error, targets []error) bool {
+	for _, target := range targets {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
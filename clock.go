@@ -0,0 +1,103 @@
+package retry
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Clock is a source of time. Everything in this package that reads the time
+// or sleeps does so using the current Clock, which is the real clock unless
+// replaced using SetClock. Replacing it with a fake allows code using the
+// package's Timers to be tested without actually sleeping.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// Sleep pauses the calling goroutine for at least the given duration.
+	Sleep(d time.Duration)
+	// NewTimer returns a ClockTimer that fires after the given duration.
+	NewTimer(d time.Duration) ClockTimer
+}
+
+// ClockTimer is a single event created by a Clock, like a time.Timer.
+type ClockTimer interface {
+	// C returns the channel on which the time is delivered when the timer
+	// fires.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing. It reports false if the timer has
+	// already fired or been stopped.
+	Stop() bool
+}
+
+// clockBox holds the current Clock so that it can be stored atomically.
+type clockBox struct {
+	Clock
+}
+
+var clock atomic.Pointer[clockBox]
+
+func init() {
+	clock.Store(&clockBox{realClock{}})
+}
+
+// SetClock replaces the current Clock and returns a function that restores
+// the previous one. It is intended for tests; Timers and Limiters in use
+// switch to the new Clock immediately, which is rarely meaningful.
+func SetClock(c Clock) (restore func()) {
+	prev := clock.Swap(&clockBox{c})
+	return func() {
+		clock.Store(prev)
+	}
+}
+
+// CurrentClock returns the current Clock, for packages building on this one
+// that read the time or sleep outside of its loops.
+func CurrentClock() Clock {
+	return clock.Load().Clock
+}
+
+// realClock is the Clock backed by package time.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+func (realClock) NewTimer(d time.Duration) ClockTimer {
+	return realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	t *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t realTimer) Stop() bool {
+	return t.t.Stop()
+}
+
+// now returns the current time of the current Clock.
+func now() time.Time {
+	return clock.Load().Now()
+}
+
+// since returns the time elapsed since t according to the current Clock.
+func since(t time.Time) time.Duration {
+	return now().Sub(t)
+}
+
+// until returns the duration until t according to the current Clock.
+func until(t time.Time) time.Duration {
+	return t.Sub(now())
+}
+
+// sleep pauses for the given duration using the current Clock.
+func sleep(d time.Duration) {
+	clock.Load().Sleep(d)
+}
@@ -0,0 +1,86 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// All returns a Limiter that allows a further attempt only if every one of the
+// given Limiters does. Every Limiter is evaluated for every failed attempt,
+// even once the outcome is known, so that stateful Limiters such as Counts
+// observe each attempt. With no Limiters the loop is never terminated.
+func All(limiters ...Limiter) Limiter {
+	return func(err error) bool {
+		ok := true
+		for _, l := range limiters {
+			if !l(err) {
+				ok = false
+			}
+		}
+		return ok
+	}
+}
+
+// Any returns a Limiter that allows a further attempt if any one of the given
+// Limiters does. As with All, every Limiter is evaluated for every failed
+// attempt. With no Limiters the loop is always terminated.
+func Any(limiters ...Limiter) Limiter {
+	return func(err error) bool {
+		ok := false
+		for _, l := range limiters {
+			if l(err) {
+				ok = true
+			}
+		}
+		return ok
+	}
+}
+
+// Not returns a Limiter that allows a further attempt only if the given
+// Limiter does not.
+func Not(limiter Limiter) Limiter {
+	return func(err error) bool {
+		return !limiter(err)
+	}
+}
+
+// Sequence returns a Timer that calls first for the first n times it is
+// called and then for every call thereafter. For example, three quick
+// retries followed by exponential backoff:
+//
+//	retry.Sequence(retry.CancelableSleep(ctx, 100*time.Millisecond), 3,
+//		retry.CancelableExponentialBackoff(ctx, time.Second, 2, time.Minute))
+func Sequence(first Timer, n int, then Timer) Timer {
+	var c int
+	return func() {
+		if c < n {
+			c++
+			first()
+			return
+		}
+		then()
+	}
+}
+
+// Schedule returns a Timer that sleeps for each of the given durations in
+// turn and then calls then for every call thereafter.
+func Schedule(delays []time.Duration, then Timer) Timer {
+	return Sequence(sleeper(schedule(delays)), len(delays), then)
+}
+
+// CancelableSchedule is the same as Schedule but its sleeps can be canceled
+// using a context.
+func CancelableSchedule(ctx context.Context, delays []time.Duration, then Timer) Timer {
+	return Sequence(cancelableSleeper(ctx, schedule(delays)), len(delays), then)
+}
+
+// schedule returns a function returning each of the given durations in turn.
+func schedule(delays []time.Duration) func() time.Duration {
+	delays = append([]time.Duration(nil), delays...)
+	var i int
+	return func() time.Duration {
+		dur := delays[i]
+		i++
+		return dur
+	}
+}
@@ -0,0 +1,211 @@
+package retry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Backoff kinds understood by Config.
+const (
+	BackoffConstant    = "constant"
+	BackoffExponential = "exponential"
+	BackoffLinear      = "linear"
+	BackoffFibonacci   = "fibonacci"
+)
+
+// Config is a declarative description of a retry policy, suitable for loading
+// from a JSON or YAML file or from flags. Zero fields take their defaults, so
+// the zero Config retries forever without sleeping.
+type Config struct {
+	// MaxAttempts limits the number of attempts, as with Counts. Zero means
+	// no limit.
+	MaxAttempts int `json:"max_attempts,omitempty" yaml:"max_attempts,omitempty"`
+	// MaxElapsed limits the duration of the loop, as with MaxElapsed. Zero
+	// means no limit.
+	MaxElapsed Duration `json:"max_elapsed,omitempty" yaml:"max_elapsed,omitempty"`
+	// Backoff is the kind of backoff between attempts: one of
+	// BackoffConstant, the default, BackoffExponential, BackoffLinear or
+	// BackoffFibonacci.
+	Backoff string `json:"backoff,omitempty" yaml:"backoff,omitempty"`
+	// Base is the first delay, and the only one for constant backoff.
+	Base Duration `json:"base,omitempty" yaml:"base,omitempty"`
+	// Ceiling is the greatest delay of the growing kinds of backoff, for
+	// which it is required.
+	Ceiling Duration `json:"ceiling,omitempty" yaml:"ceiling,omitempty"`
+	// Factor is the multiplier of exponential backoff. It defaults to 2.
+	Factor float64 `json:"factor,omitempty" yaml:"factor,omitempty"`
+	// Increment is the growth of linear backoff. It defaults to Base.
+	Increment Duration `json:"increment,omitempty" yaml:"increment,omitempty"`
+	// Jitter randomizes the delays of any kind of backoff.
+	Jitter Jitter `json:"jitter,omitempty" yaml:"jitter,omitempty"`
+}
+
+// Validate reports the first problem with the Config, if any.
+func (c Config) Validate() error {
+	_, _, err := c.policies()
+	return err
+}
+
+// FromConfig returns a Limiter and Timer implementing the policy described by
+// cfg, or an error describing why cfg is invalid. Like those returned by
+// Counts and the backoff constructors they track a single loop; use
+// ConfigPolicies to obtain policies that may be shared.
+func FromConfig(cfg Config) (Limiter, Timer, error) {
+	limiter, timer, err := cfg.policies()
+	if err != nil {
+		return nil, nil, err
+	}
+	return limiter(), timer(context.Background()), nil
+}
+
+// ConfigPolicies is the same as FromConfig but returns policies.
+func ConfigPolicies(cfg Config) (LimiterPolicy, TimerPolicy, error) {
+	return cfg.policies()
+}
+
+func (c Config) policies() (LimiterPolicy, TimerPolicy, error) {
+	if c.MaxAttempts < 0 {
+		return nil, nil, configError("max_attempts must not be negative")
+	}
+	if c.MaxElapsed < 0 {
+		return nil, nil, configError("max_elapsed must not be negative")
+	}
+	if c.Base < 0 {
+		return nil, nil, configError("base must not be negative")
+	}
+	if c.Jitter < NoJitter || c.Jitter > DecorrelatedJitter {
+		return nil, nil, configError("unknown jitter")
+	}
+	next, err := c.delays()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	maxAttempts, maxElapsed := c.MaxAttempts, time.Duration(c.MaxElapsed)
+	limiter := func() Limiter {
+		var limiters []Limiter
+		if maxAttempts > 0 {
+			limiters = append(limiters, Counts(maxAttempts))
+		}
+		if maxElapsed > 0 {
+			limiters = append(limiters, MaxElapsed(maxElapsed))
+		}
+		return All(limiters...)
+	}
+	timer := func(ctx context.Context) Timer {
+		return cancelableSleeper(ctx, next())
+	}
+	return limiter, timer, nil
+}
+
+// Delay returns the delay the Config's backoff would sleep after the nth
+// failed attempt of a loop, counting from one. It allows a loop whose state
+// cannot be kept in memory, such as one resumed after a restart, to follow
+// the Config. With jitter each call returns a new random delay.
+func (c Config) Delay(n int) (time.Duration, error) {
+	if err := c.Validate(); err != nil {
+		return 0, err
+	}
+	next, _ := c.delays()
+	delay := next()
+	var d time.Duration
+	for i := 0; i < n; i++ {
+		d = delay()
+	}
+	return d, nil
+}
+
+// delays returns a constructor of the function computing successive delays
+// of the Config's backoff.
+func (c Config) delays() (func() func() time.Duration, error) {
+	base, ceil := time.Duration(c.Base), time.Duration(c.Ceiling)
+	var next func() func() time.Duration
+	switch c.Backoff {
+	case "", BackoffConstant:
+		ceil = base
+		next = func() func() time.Duration {
+			return func() time.Duration {
+				return base
+			}
+		}
+	case BackoffExponential:
+		factor := c.Factor
+		if factor == 0 {
+			factor = 2
+		}
+		if factor <= 1 {
+			return nil, configError("factor must be greater than 1")
+		}
+		next = func() func() time.Duration {
+			return exponential(base, factor, ceil)
+		}
+	case BackoffLinear:
+		increment := time.Duration(c.Increment)
+		if increment == 0 {
+			increment = base
+		}
+		if increment < 0 {
+			return nil, configError("increment must not be negative")
+		}
+		next = func() func() time.Duration {
+			return linear(base, increment, ceil)
+		}
+	case BackoffFibonacci:
+		next = func() func() time.Duration {
+			return fibonacci(base, ceil)
+		}
+	default:
+		return nil, configError(fmt.Sprintf("unknown backoff %q", c.Backoff))
+	}
+	if ceil < base {
+		return nil, configError("ceiling must not be less than base")
+	}
+	if c.Jitter == NoJitter {
+		return next, nil
+	}
+	jitter := c.Jitter
+	return func() func() time.Duration {
+		return jittered(next(), base, ceil, jitter)
+	}, nil
+}
+
+func configError(msg string) error {
+	return errors.New("retry: invalid config: " + msg)
+}
+
+// Duration is a time.Duration that is encoded as a string such as "1m30s",
+// for use in configuration files. When decoding JSON a number is also
+// accepted, as a number of nanoseconds.
+type Duration time.Duration
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	dur, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("retry: invalid duration: %w", err)
+	}
+	*d = Duration(dur)
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var n int64
+	if err := json.Unmarshal(data, &n); err == nil {
+		*d = Duration(n)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("retry: invalid duration: %s", data)
+	}
+	return d.UnmarshalText([]byte(s))
+}
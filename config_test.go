@@ -0,0 +1,145 @@
+package retry_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/colvin/retry"
+)
+
+func TestConfigDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    retry.Config
+		wantErr bool
+	}{
+		{
+			name: "string durations",
+			json: `{"max_attempts":5,"max_elapsed":"1m30s","backoff":"exponential","base":"100ms","ceiling":"10s","factor":3}`,
+			want: retry.Config{
+				MaxAttempts: 5,
+				MaxElapsed:  retry.Duration(90 * time.Second),
+				Backoff:     retry.BackoffExponential,
+				Base:        retry.Duration(100 * time.Millisecond),
+				Ceiling:     retry.Duration(10 * time.Second),
+				Factor:      3,
+			},
+		},
+		{
+			name: "numeric durations",
+			json: `{"base":1000000,"increment":2000000000,"backoff":"linear"}`,
+			want: retry.Config{
+				Backoff:   retry.BackoffLinear,
+				Base:      retry.Duration(time.Millisecond),
+				Increment: retry.Duration(2 * time.Second),
+			},
+		},
+		{name: "no jitter", json: `{"jitter":"none"}`, want: retry.Config{Jitter: retry.NoJitter}},
+		{name: "full jitter", json: `{"jitter":"full"}`, want: retry.Config{Jitter: retry.FullJitter}},
+		{name: "equal jitter", json: `{"jitter":"equal"}`, want: retry.Config{Jitter: retry.EqualJitter}},
+		{name: "decorrelated jitter", json: `{"jitter":"decorrelated"}`, want: retry.Config{Jitter: retry.DecorrelatedJitter}},
+		{name: "unknown jitter", json: `{"jitter":"some"}`, wantErr: true},
+		{name: "invalid duration", json: `{"base":"soon"}`, wantErr: true},
+		{name: "invalid duration type", json: `{"base":true}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got retry.Config
+			err := json.Unmarshal([]byte(tt.json), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("decoded %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decoded %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  retry.Config
+		want string
+	}{
+		{name: "zero", cfg: retry.Config{}},
+		{name: "exponential", cfg: retry.Config{Backoff: retry.BackoffExponential, Base: retry.Duration(time.Second), Ceiling: retry.Duration(time.Minute)}},
+		{name: "negative attempts", cfg: retry.Config{MaxAttempts: -1}, want: "retry: invalid config: max_attempts must not be negative"},
+		{name: "negative elapsed", cfg: retry.Config{MaxElapsed: -1}, want: "retry: invalid config: max_elapsed must not be negative"},
+		{name: "negative base", cfg: retry.Config{Base: -1}, want: "retry: invalid config: base must not be negative"},
+		{name: "unknown jitter", cfg: retry.Config{Jitter: 9}, want: "retry: invalid config: unknown jitter"},
+		{name: "unknown backoff", cfg: retry.Config{Backoff: "cubic"}, want: `retry: invalid config: unknown backoff "cubic"`},
+		{name: "small factor", cfg: retry.Config{Backoff: retry.BackoffExponential, Factor: 1, Ceiling: 1}, want: "retry: invalid config: factor must be greater than 1"},
+		{name: "negative increment", cfg: retry.Config{Backoff: retry.BackoffLinear, Increment: -1, Ceiling: 1}, want: "retry: invalid config: increment must not be negative"},
+		{name: "missing ceiling", cfg: retry.Config{Backoff: retry.BackoffFibonacci, Base: retry.Duration(time.Second)}, want: "retry: invalid config: ceiling must not be less than base"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Validate = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("Validate = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigDelay(t *testing.T) {
+	second, minute := retry.Duration(time.Second), retry.Duration(time.Minute)
+	tests := []struct {
+		name string
+		cfg  retry.Config
+		want []time.Duration
+	}{
+		{
+			name: "constant",
+			cfg:  retry.Config{Base: second},
+			want: []time.Duration{time.Second, time.Second, time.Second},
+		},
+		{
+			name: "exponential",
+			cfg:  retry.Config{Backoff: retry.BackoffExponential, Base: second, Ceiling: retry.Duration(5 * time.Second)},
+			want: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second},
+		},
+		{
+			name: "linear",
+			cfg:  retry.Config{Backoff: retry.BackoffLinear, Base: second, Ceiling: minute},
+			want: []time.Duration{time.Second, 2 * time.Second, 3 * time.Second},
+		},
+		{
+			name: "fibonacci",
+			cfg:  retry.Config{Backoff: retry.BackoffFibonacci, Base: second, Ceiling: minute},
+			want: []time.Duration{time.Second, time.Second, 2 * time.Second, 3 * time.Second, 5 * time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, want := range tt.want {
+				got, err := tt.cfg.Delay(i + 1)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != want {
+					t.Errorf("Delay(%d) = %v, want %v", i+1, got, want)
+				}
+			}
+		})
+	}
+	if _, err := (retry.Config{MaxAttempts: -1}).Delay(1); err == nil {
+		t.Error("Delay of an invalid Config returned no error")
+	}
+}
@@ -0,0 +1,61 @@
+package retry
+
+import (
+	"context"
+	"errors"
+)
+
+// Outcome reports how Process disposed of a message.
+type Outcome int
+
+const (
+	// Processed means that the handler succeeded.
+	Processed Outcome = iota
+	// DeadLettered means that the handler gave up and the message was
+	// handed to the dead-letter function.
+	DeadLettered
+	// Unprocessed means that the message was neither processed nor
+	// dead-lettered, because the context was done or the dead-letter
+	// function failed. It should typically be left to be redelivered.
+	Unprocessed
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Processed:
+		return "processed"
+	case DeadLettered:
+		return "dead-lettered"
+	case Unprocessed:
+		return "unprocessed"
+	}
+	return "unknown"
+}
+
+// Process handles a message taken from a queue, retrying handler in a loop
+// of r. If the loop gives up, dlq is called exactly once with the message and
+// the final error, for example to publish it to a dead-letter queue, unless
+// the loop was canceled, because ctx is done or r's Controller stopped it, in
+// which case the message is left unprocessed. A shutdown never dead-letters a
+// message.
+//
+// Process returns Processed and nil if the handler succeeded, DeadLettered
+// and the final error if dlq succeeded, and Unprocessed otherwise, with the
+// final error joined with that of dlq, if any. A fallback configured on r
+// that recovers from the failure makes the message Processed.
+func Process[M any](ctx context.Context, msg M, handler func(context.Context, M) error, r *Retryer, dlq func(M, error) error) (Outcome, error) {
+	err := r.DoContext(ctx, func(ctx context.Context) error {
+		return handler(ctx, msg)
+	})
+	if err == nil {
+		return Processed, nil
+	}
+	var ce *CanceledError
+	if ctx.Err() != nil || errors.Is(err, ErrStopped) || errors.As(err, &ce) {
+		return Unprocessed, err
+	}
+	if dlqErr := dlq(msg, err); dlqErr != nil {
+		return Unprocessed, errors.Join(err, dlqErr)
+	}
+	return DeadLettered, err
+}
@@ -0,0 +1,95 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestProcess(t *testing.T) {
+	errDLQ := errors.New("dlq")
+	tests := []struct {
+		name    string
+		handler func(context.Context, string) error
+		dlqErr  error
+		cancel  bool
+		want    Outcome
+		wantDLQ bool
+	}{
+		{
+			name:    "processed",
+			handler: func(context.Context, string) error { return nil },
+			want:    Processed,
+		},
+		{
+			name:    "dead-lettered",
+			handler: func(context.Context, string) error { return errTransient },
+			want:    DeadLettered,
+			wantDLQ: true,
+		},
+		{
+			name:    "dead-letter failed",
+			handler: func(context.Context, string) error { return errTransient },
+			dlqErr:  errDLQ,
+			want:    Unprocessed,
+			wantDLQ: true,
+		},
+		{
+			name:    "canceled",
+			handler: func(context.Context, string) error { return errTransient },
+			cancel:  true,
+			want:    Unprocessed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+			var dlqCalls int
+			dlq := func(msg string, err error) error {
+				dlqCalls++
+				if msg != "msg" || !errors.Is(err, errTransient) {
+					t.Errorf("dlq(%q, %v)", msg, err)
+				}
+				return tt.dlqErr
+			}
+			got, err := Process(ctx, "msg", tt.handler, New(WithMaxAttempts(3)), dlq)
+			if got != tt.want {
+				t.Errorf("outcome = %v, want %v", got, tt.want)
+			}
+			if (got == Processed) != (err == nil) {
+				t.Errorf("err = %v", err)
+			}
+			if tt.dlqErr != nil && !errors.Is(err, tt.dlqErr) {
+				t.Errorf("err = %v, want it to wrap %v", err, tt.dlqErr)
+			}
+			if tt.wantDLQ && dlqCalls != 1 || !tt.wantDLQ && dlqCalls != 0 {
+				t.Errorf("dlq called %d times", dlqCalls)
+			}
+		})
+	}
+}
+
+func TestProcessStoppedByController(t *testing.T) {
+	c := NewController()
+	r := New(WithController(c))
+	handler := func(context.Context, string) error {
+		go c.Drain()
+		<-c.stop.Done()
+		return errTransient
+	}
+	dlq := func(string, error) error {
+		t.Error("dlq called on shutdown")
+		return nil
+	}
+	got, err := Process(context.Background(), "msg", handler, r, dlq)
+	if got != Unprocessed {
+		t.Errorf("outcome = %v, want %v", got, Unprocessed)
+	}
+	if !errors.Is(err, ErrStopped) {
+		t.Errorf("err = %v, want ErrStopped", err)
+	}
+}
@@ -0,0 +1,128 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrStopped is the cause of the *CanceledError returned by a loop that was
+// shut down by its Controller.
+var ErrStopped = errors.New("retry: stopped")
+
+// Controller shuts down the loops of the Retryers configured with it, such
+// as background loops using Forever or UntilCanceled, gracefully. Once
+// stopped, its loops make no further attempts: those sleeping between
+// attempts return immediately and those making an attempt return once it
+// completes, or once the grace period passed to Stop ends and the attempt's
+// context is canceled.
+//
+// A loop shut down by its Controller without having succeeded returns a
+// *CanceledError whose Cause is ErrStopped, so that shutting down can be
+// distinguished from failure using errors.Is.
+type Controller struct {
+	stop     context.Context
+	stopFunc context.CancelFunc
+	kill     context.Context
+	killFunc context.CancelFunc
+
+	mu      sync.Mutex
+	stopped bool
+	loops   sync.WaitGroup
+}
+
+// NewController returns a Controller that has not been stopped.
+func NewController() *Controller {
+	c := &Controller{}
+	c.stop, c.stopFunc = context.WithCancel(context.Background())
+	c.kill, c.killFunc = context.WithCancel(context.Background())
+	return c
+}
+
+// WithController shuts down the loops made by the Retryer using the given
+// Controller. Loops started after the Controller is stopped make no attempts.
+func WithController(c *Controller) Option {
+	return func(r *Retryer) {
+		r.controller = c
+	}
+}
+
+// Stop stops the Controller's loops and waits for them to return. If ctx is
+// done first, the contexts of their attempts are canceled and Stop returns
+// the context's error without waiting further.
+func (c *Controller) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	c.stopped = true
+	c.mu.Unlock()
+	c.stopFunc()
+
+	done := make(chan struct{})
+	go func() {
+		c.loops.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		c.killFunc()
+		return ctx.Err()
+	}
+}
+
+// Drain stops the Controller's loops and waits for them to return, however
+// long their attempts take.
+func (c *Controller) Drain() {
+	c.Stop(context.Background())
+}
+
+// run runs a loop for worker using run as a loop of the Controller. Only a
+// loop that terminated because it was stopped returns a stopped error; one
+// that gave up for another reason returns its own error, even if the
+// Controller was stopped meanwhile.
+func (c *Controller) run(ctx context.Context, worker ContextWorker, run func(context.Context, ContextWorker) (StopReason, error)) error {
+	c.mu.Lock()
+	if c.stopped {
+		c.mu.Unlock()
+		return &CanceledError{Cause: ErrStopped}
+	}
+	c.loops.Add(1)
+	c.mu.Unlock()
+	defer c.loops.Done()
+
+	// The loop runs until stopped, but its attempts run until killed.
+	soft, cancelSoft := context.WithCancel(ctx)
+	defer cancelSoft()
+	defer context.AfterFunc(c.stop, cancelSoft)()
+	hard, cancelHard := context.WithCancel(ctx)
+	defer cancelHard()
+	defer context.AfterFunc(c.kill, cancelHard)()
+
+	reason, err := run(soft, func(actx context.Context) error {
+		if n, ok := AttemptFromContext(actx); ok {
+			return worker(withAttempt(hard, n))
+		}
+		return worker(hard)
+	})
+	if err != nil && reason == StopCanceled && ctx.Err() == nil && c.stop.Err() != nil {
+		return stopped(err)
+	}
+	return err
+}
+
+// stopped returns the error of a loop shut down by its Controller that would
+// otherwise have returned err.
+func stopped(err error) error {
+	if e, ok := err.(*ExhaustedError); ok {
+		return &ExhaustedError{
+			Attempts: e.Attempts,
+			Elapsed:  e.Elapsed,
+			Reason:   StopCanceled,
+			Err:      stopped(e.Err),
+		}
+	}
+	if e, ok := err.(*CanceledError); ok {
+		err = e.Err
+	}
+	return &CanceledError{Cause: ErrStopped, Err: err}
+}
@@ -0,0 +1,143 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errTransient = errors.New("transient")
+
+func TestControllerStopWaitsForAttempt(t *testing.T) {
+	c := NewController()
+	r := New(WithController(c))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		var once bool
+		done <- r.Do(func() error {
+			if !once {
+				once = true
+				close(started)
+				<-release
+			}
+			return errTransient
+		})
+	}()
+	<-started
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- c.Stop(context.Background())
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned before the attempt completed")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(release)
+
+	if err := <-stopped; err != nil {
+		t.Errorf("Stop = %v, want nil", err)
+	}
+	err := <-done
+	var ce *CanceledError
+	if !errors.As(err, &ce) || !errors.Is(err, ErrStopped) || !errors.Is(err, errTransient) {
+		t.Errorf("err = %v, want a *CanceledError wrapping ErrStopped and the attempt's error", err)
+	}
+}
+
+func TestControllerStopCancelsAttemptsAfterGrace(t *testing.T) {
+	c := NewController()
+	r := New(WithController(c))
+
+	started := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- r.DoContext(context.Background(), func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}()
+	<-started
+
+	grace, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := c.Stop(grace); err != context.DeadlineExceeded {
+		t.Errorf("Stop = %v, want %v", err, context.DeadlineExceeded)
+	}
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrStopped) || !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want ErrStopped wrapping the attempt's cancellation", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("attempt was not canceled once the grace period ended")
+	}
+}
+
+func TestControllerDrain(t *testing.T) {
+	c := NewController()
+	r := New(WithController(c), WithTimer(SleepPolicy(time.Hour)))
+
+	failed := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Do(func() error {
+			select {
+			case <-failed:
+			default:
+				close(failed)
+			}
+			return errTransient
+		})
+	}()
+	<-failed
+
+	c.Drain()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrStopped) {
+			t.Errorf("err = %v, want ErrStopped", err)
+		}
+	default:
+		t.Fatal("Drain returned before the loop")
+	}
+}
+
+func TestControllerLoopAfterStop(t *testing.T) {
+	c := NewController()
+	c.Drain()
+
+	var attempts int
+	err := New(WithController(c)).Do(func() error {
+		attempts++
+		return nil
+	})
+	if attempts != 0 {
+		t.Errorf("attempts = %d, want 0", attempts)
+	}
+	if !errors.Is(err, ErrStopped) {
+		t.Errorf("err = %v, want ErrStopped", err)
+	}
+}
+
+func TestControllerLimiterGivesUpBeforeStop(t *testing.T) {
+	c := NewController()
+	// The Controller is stopped once the Limiter has given up but before
+	// the loop has returned.
+	stopOnGiveUp := OnGiveUp(func(Attempt) {
+		go c.Drain()
+		<-c.stop.Done()
+	})
+	err := New(WithController(c), WithMaxAttempts(2), stopOnGiveUp).Do(func() error {
+		return errTransient
+	})
+	if err != errTransient {
+		t.Errorf("err = %v, want %v", err, errTransient)
+	}
+}
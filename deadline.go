@@ -0,0 +1,56 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// DeadlineAwareBackoff returns a Timer that backs off like
+// MultiplicativeBackoff but never sleeps past the deadline of ctx: each sleep
+// is the lesser of the backoff and the time remaining. Once no time remains
+// it returns immediately, and as the deadline has then passed the loop makes
+// no further attempt. This is how CancelableMultiplicativeBackoff behaves;
+// DeadlineAwareBackoff names the behavior for loops that rely on it.
+func DeadlineAwareBackoff(ctx context.Context, base time.Duration, ceil time.Duration) Timer {
+	return CancelableMultiplicativeBackoff(ctx, base, ceil)
+}
+
+// DeadlineLimiter returns a LimiterFunc that terminates the loop when the
+// time remaining before the deadline of ctx is less than the expected
+// duration of a further attempt plus the wait before it, so that the last of
+// the time is not spent on an attempt that cannot complete. Attempts are
+// expected to take a moving average of the durations of those before them.
+// The Timer's next wait cannot be known in advance, so it is extrapolated
+// from the last two waits, assuming the backoff grows by the same factor as
+// it last did; a delay specified by the error is used as is. The wait before
+// the second attempt, having no earlier waits to go by, is taken to be zero,
+// and jittered waits are only estimated roughly. Without a deadline it allows
+// every attempt.
+func DeadlineLimiter(ctx context.Context) LimiterFunc {
+	var estimate, prevDelay time.Duration
+	return func(a Attempt) bool {
+		if a.Number == 1 {
+			estimate = a.Duration
+		} else {
+			estimate += (a.Duration - estimate) / 4
+		}
+		next := a.Delay
+		if prevDelay > 0 {
+			next = time.Duration(float64(a.Delay) * float64(a.Delay) / float64(prevDelay))
+		}
+		prevDelay = a.Delay
+		if after, ok := RetryAfter(a.Err); ok {
+			next = after
+		}
+		deadline, ok := ctx.Deadline()
+		return !ok || until(deadline) >= estimate+next
+	}
+}
+
+// WithDeadlineLimiter terminates each loop of the Retryer as with
+// DeadlineLimiter.
+func WithDeadlineLimiter() Option {
+	return func(r *Retryer) {
+		r.deadlineLimiter = true
+	}
+}
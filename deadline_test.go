@@ -0,0 +1,79 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/colvin/retry"
+	"github.com/colvin/retry/retrytest"
+)
+
+func TestDeadlineAwareBackoffTruncatesSleep(t *testing.T) {
+	clock := retrytest.NewClock(time.Now())
+	defer clock.Install()()
+	ctx, cancel := context.WithDeadline(context.Background(), clock.Now().Add(time.Hour))
+	defer cancel()
+
+	timer := retry.DeadlineAwareBackoff(ctx, 40*time.Minute, 2*time.Hour)
+	timer()
+	timer()
+
+	want := []time.Duration{40 * time.Minute, 20 * time.Minute}
+	if got := clock.Sleeps(); !reflect.DeepEqual(got, want) {
+		t.Errorf("sleeps = %v, want %v", got, want)
+	}
+}
+
+func TestDeadlineAwareBackoffZeroRemaining(t *testing.T) {
+	clock := retrytest.NewClock(time.Now())
+	defer clock.Install()()
+	ctx, cancel := context.WithDeadline(context.Background(), clock.Now().Add(time.Hour))
+	defer cancel()
+	clock.Advance(time.Hour)
+
+	timer := retry.DeadlineAwareBackoff(ctx, time.Minute, time.Hour)
+	timer()
+	if got := clock.Sleeps(); len(got) != 0 {
+		t.Errorf("sleeps = %v, want none", got)
+	}
+
+	var attempts int
+	errFail := errors.New("fail")
+	err := retry.RetryContext(ctx, func(context.Context) error {
+		attempts++
+		return errFail
+	}, retry.Forever(), timer)
+	if err != errFail {
+		t.Errorf("err = %v, want %v", err, errFail)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
+
+func TestDeadlineLimiterExtrapolatesBackoff(t *testing.T) {
+	clock := retrytest.NewClock(time.Now())
+	defer clock.Install()()
+	ctx, cancel := context.WithDeadline(context.Background(), clock.Now().Add(time.Hour))
+	defer cancel()
+
+	// Attempts take a minute and the backoff doubles from five minutes.
+	// The fourth attempt ends after 39m, when a wait of 40m is expected
+	// before the fifth, which could not finish in the 21m remaining.
+	var attempts int
+	retry.New(
+		retry.WithContext(ctx),
+		retry.WithBackoff(5*time.Minute, time.Hour),
+		retry.WithDeadlineLimiter(),
+	).Do(func() error {
+		attempts++
+		clock.Advance(time.Minute)
+		return errors.New("fail")
+	})
+	if attempts != 4 {
+		t.Errorf("attempts = %d, want 4", attempts)
+	}
+}
@@ -0,0 +1,63 @@
+package retry
+
+import (
+	"runtime/debug"
+	"sync"
+)
+
+// Deduper coalesces concurrent retry loops for the same key, such as many
+// goroutines refreshing the same token, into one. It is safe for concurrent
+// use.
+type Deduper struct {
+	r     *Retryer
+	mu    sync.Mutex
+	calls map[string]*dedupeCall
+}
+
+type dedupeCall struct {
+	done chan struct{}
+	err  error
+}
+
+// NewDeduper returns a Deduper whose loops are made by r.
+func NewDeduper(r *Retryer) *Deduper {
+	return &Deduper{
+		r:     r,
+		calls: make(map[string]*dedupeCall),
+	}
+}
+
+// Do runs a retry loop for worker, unless a loop for the same key is already
+// running, in which case it waits for that loop to finish instead. Either way
+// the result of the loop is returned. If the Worker of the running loop
+// panics, the panic propagates to the caller that started it and the others
+// receive a *PanicError.
+func (d *Deduper) Do(key string, worker Worker) error {
+	d.mu.Lock()
+	if c, ok := d.calls[key]; ok {
+		d.mu.Unlock()
+		<-c.done
+		return c.err
+	}
+	c := &dedupeCall{done: make(chan struct{})}
+	d.calls[key] = c
+	d.mu.Unlock()
+
+	defer func() {
+		if v := recover(); v != nil {
+			c.err = &PanicError{Value: v, Stack: debug.Stack()}
+			d.finish(key, c)
+			panic(v)
+		}
+		d.finish(key, c)
+	}()
+	c.err = d.r.Do(worker)
+	return c.err
+}
+
+func (d *Deduper) finish(key string, c *dedupeCall) {
+	d.mu.Lock()
+	delete(d.calls, key)
+	d.mu.Unlock()
+	close(c.done)
+}
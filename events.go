@@ -0,0 +1,73 @@
+package retry
+
+import "time"
+
+// EventKind is the kind of an AttemptEvent.
+type EventKind int
+
+const (
+	// EventStart is emitted when an attempt starts.
+	EventStart EventKind = iota
+	// EventFailure is emitted when an attempt fails.
+	EventFailure
+	// EventSleep is emitted when the wait between two attempts has finished.
+	EventSleep
+	// EventSuccess is emitted when an attempt succeeds.
+	EventSuccess
+	// EventGiveUp is emitted when a loop terminates without success.
+	EventGiveUp
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventStart:
+		return "start"
+	case EventFailure:
+		return "failure"
+	case EventSleep:
+		return "sleep"
+	case EventSuccess:
+		return "success"
+	case EventGiveUp:
+		return "give-up"
+	}
+	return "unknown"
+}
+
+// AttemptEvent describes something that happened during a retry loop.
+type AttemptEvent struct {
+	// Kind is the kind of event.
+	Kind EventKind
+	// Attempt is the number of the attempt concerned, starting at one. For
+	// EventSleep it is the attempt that failed before the wait.
+	Attempt int
+	// Err is the error of the attempt, for EventFailure and EventGiveUp.
+	Err error
+	// Delay is the time spent waiting, for EventSleep.
+	Delay time.Duration
+	// Start is the time at which the loop started.
+	Start time.Time
+	// Time is the time at which the event occurred.
+	Time time.Time
+}
+
+// WithEvents sends an AttemptEvent for everything that happens in the
+// Retryer's loops on ch. Sends never block: if ch is not ready to receive,
+// the event is dropped, so ch should be buffered.
+func WithEvents(ch chan<- AttemptEvent) Option {
+	return WithEventFunc(func(e AttemptEvent) {
+		select {
+		case ch <- e:
+		default:
+		}
+	})
+}
+
+// WithEventFunc calls fn with an AttemptEvent for everything that happens in
+// the Retryer's loops. It is called synchronously by the loop, so it should
+// return promptly.
+func WithEventFunc(fn func(AttemptEvent)) Option {
+	return func(r *Retryer) {
+		r.hooks.onEvent = append(r.hooks.onEvent, fn)
+	}
+}
@@ -0,0 +1,78 @@
+package retry
+
+import (
+	"fmt"
+	"time"
+)
+
+// StopReason is the reason a loop terminated without success.
+type StopReason int
+
+const (
+	// StopLimiter means that the Limiter refused a further attempt.
+	StopLimiter StopReason = iota
+	// StopPermanent means that an attempt returned a permanent error.
+	StopPermanent
+	// StopCanceled means that the loop's context was done or its deadline
+	// had passed.
+	StopCanceled
+)
+
+func (r StopReason) String() string {
+	switch r {
+	case StopLimiter:
+		return "limiter"
+	case StopPermanent:
+		return "permanent"
+	case StopCanceled:
+		return "canceled"
+	}
+	return "unknown"
+}
+
+// ExhaustedError is returned by a loop of a Retryer configured using
+// WithExhaustedError that terminated without success. It describes the loop
+// and wraps the error the loop would otherwise have returned.
+type ExhaustedError struct {
+	// Attempts is the number of attempts made.
+	Attempts int
+	// Elapsed is the time between the start of the first attempt and the
+	// end of the last.
+	Elapsed time.Duration
+	// Reason is the reason the loop terminated.
+	Reason StopReason
+	// Err is the error the loop would otherwise have returned.
+	Err error
+}
+
+func (e *ExhaustedError) Error() string {
+	return fmt.Sprintf("retry: gave up after %d attempts (%s): %v", e.Attempts, e.Reason, e.Err)
+}
+
+func (e *ExhaustedError) Unwrap() error {
+	return e.Err
+}
+
+// WithExhaustedError returns a *ExhaustedError in place of the error of a
+// loop that terminates without success.
+func WithExhaustedError() Option {
+	return func(r *Retryer) {
+		r.exhaustedError = true
+	}
+}
+
+// exhausted returns the error with which the loop terminates after the
+// attempt a for the given reason, err being the error it would otherwise
+// return. The reason is recorded in the loop.
+func (l *loop) exhausted(a Attempt, reason StopReason, err error) error {
+	l.reason = reason
+	if !l.exhaustedError {
+		return err
+	}
+	return &ExhaustedError{
+		Attempts: a.Number,
+		Elapsed:  a.Elapsed,
+		Reason:   reason,
+		Err:      err,
+	}
+}
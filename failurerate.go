@@ -0,0 +1,111 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// failureRateBuckets is the number of buckets into which a FailureRate
+// divides its window.
+const failureRateBuckets = 10
+
+// FailureRate tracks the proportion of attempts that failed over a sliding
+// window of time, across many loops, so that they can give up early when a
+// dependency is down rather than merely flaky. The window slides in steps of
+// a tenth of its length.
+//
+// Loops use a FailureRate by wrapping their Worker with FailureRate.Worker,
+// which records the outcome of each attempt, and their Limiter with
+// FailureRate.Limiter. It is safe for concurrent use.
+type FailureRate struct {
+	mu         sync.Mutex
+	width      time.Duration
+	threshold  float64
+	minSamples int
+	buckets    [failureRateBuckets]rateBucket
+}
+
+// rateBucket counts the attempts of one step of a FailureRate's window.
+type rateBucket struct {
+	start     time.Time
+	successes int
+	failures  int
+}
+
+// NewFailureRate returns a FailureRate over the given window whose Limiters
+// terminate their loops once more than threshold, a proportion between zero
+// and one, of the attempts in the window have failed. No loop is terminated
+// until the window holds at least minSamples attempts.
+func NewFailureRate(window time.Duration, threshold float64, minSamples int) *FailureRate {
+	width := window / failureRateBuckets
+	if width <= 0 {
+		width = 1
+	}
+	return &FailureRate{
+		width:      width,
+		threshold:  threshold,
+		minSamples: minSamples,
+	}
+}
+
+// Rate returns the proportion of the attempts in the window that failed, and
+// the number of attempts.
+func (f *FailureRate) Rate() (float64, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	cutoff := now().Add(-f.width * failureRateBuckets)
+	var successes, failures int
+	for _, b := range f.buckets {
+		if b.start.After(cutoff) {
+			successes += b.successes
+			failures += b.failures
+		}
+	}
+	total := successes + failures
+	if total == 0 {
+		return 0, 0
+	}
+	return float64(failures) / float64(total), total
+}
+
+// Limiter returns a Limiter that wraps another Limiter, terminating the loop
+// if the failure rate exceeds the threshold.
+func (f *FailureRate) Limiter(limiter Limiter) Limiter {
+	return func(err error) bool {
+		if !limiter(err) {
+			return false
+		}
+		rate, n := f.Rate()
+		return n < f.minSamples || rate <= f.threshold
+	}
+}
+
+// Worker returns a Worker that records the outcome of each attempt of worker
+// in the FailureRate.
+func (f *FailureRate) Worker(worker Worker) Worker {
+	return func() error {
+		err := worker()
+		f.record(err == nil)
+		return err
+	}
+}
+
+func (f *FailureRate) record(success bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t := now()
+	start := t.Truncate(f.width)
+	i := int(start.UnixNano() / int64(f.width) % failureRateBuckets)
+	if i < 0 {
+		i += failureRateBuckets
+	}
+	b := &f.buckets[i]
+	if !b.start.Equal(start) {
+		*b = rateBucket{start: start}
+	}
+	if success {
+		b.successes++
+	} else {
+		b.failures++
+	}
+}
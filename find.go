@@ -0,0 +1,34 @@
+package retry
+
+import "errors"
+
+// find returns the first error in err's chain that is a T, as errors.As does
+// for a target of type *T. Unlike errors.As it does not allocate unless an
+// error in the chain has an As method, in which case it defers to errors.As.
+func find[T any](err error) (T, bool) {
+	var zero T
+	for err != nil {
+		if t, ok := err.(T); ok {
+			return t, true
+		}
+		if _, ok := err.(interface{ As(interface{}) bool }); ok {
+			var t T
+			ok := errors.As(err, &t)
+			return t, ok
+		}
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, err := range x.Unwrap() {
+				if t, ok := find[T](err); ok {
+					return t, true
+				}
+			}
+			return zero, false
+		default:
+			return zero, false
+		}
+	}
+	return zero, false
+}
@@ -0,0 +1,69 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Fixed is a retry policy making up to a fixed number of attempts separated
+// by a constant delay. It is the equivalent of Retry with Counts and a Timer
+// sleeping for the delay, such as CancelableSleep, but as a value keeping the
+// state of each loop on the stack it makes loops that allocate nothing beyond
+// what the Worker allocates, for use on hot paths. A Fixed may be shared by any number of concurrent loops.
+//
+// As in the other loops, a permanent error terminates the loop immediately,
+// and a delay specified by the error is slept for in place of Delay; see
+// RetryAfterError.
+type Fixed struct {
+	// Attempts is the greatest number of attempts. Less than one means one.
+	Attempts int
+	// Delay is the sleep between attempts.
+	Delay time.Duration
+}
+
+// Do runs a retry loop for the given Worker.
+func (f Fixed) Do(worker Worker) error {
+	for n := 1; ; n++ {
+		err := worker()
+		if err == nil {
+			return nil
+		}
+		if perm, ok := unwrapPermanent(err); ok {
+			return perm
+		}
+		if n >= f.Attempts {
+			return err
+		}
+		if dur, ok := RetryAfter(err); ok {
+			sleep(dur)
+		} else if f.Delay > 0 {
+			sleep(f.Delay)
+		}
+	}
+}
+
+// DoContext runs a retry loop for the given ContextWorker. No further attempts
+// are made once ctx is done or its deadline has passed, and the sleeps are
+// canceled by it. Unlike Do, its sleeps allocate a timer.
+func (f Fixed) DoContext(ctx context.Context, worker ContextWorker) error {
+	for n := 1; ; n++ {
+		err := worker(ctx)
+		if err == nil {
+			return nil
+		}
+		if perm, ok := unwrapPermanent(err); ok {
+			return perm
+		}
+		if n >= f.Attempts || expired(ctx) {
+			return err
+		}
+		if dur, ok := RetryAfter(err); ok {
+			sleepContext(ctx, dur)
+		} else if f.Delay > 0 {
+			sleepContext(ctx, f.Delay)
+		}
+		if expired(ctx) {
+			return err
+		}
+	}
+}
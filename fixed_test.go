@@ -0,0 +1,68 @@
+package retry
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var errBenchmark = errors.New("fail")
+
+// failTwice returns a Worker that fails twice and then succeeds, repeatedly.
+func failTwice() Worker {
+	var n int
+	return func() error {
+		n++
+		if n%3 != 0 {
+			return errBenchmark
+		}
+		return nil
+	}
+}
+
+func TestFixed(t *testing.T) {
+	var attempts int
+	err := Fixed{Attempts: 3}.Do(func() error {
+		attempts++
+		return errBenchmark
+	})
+	if err != errBenchmark || attempts != 3 {
+		t.Errorf("err, attempts = %v, %d, want %v, 3", err, attempts, errBenchmark)
+	}
+
+	attempts = 0
+	errPermanent := errors.New("permanent")
+	err = Fixed{Attempts: 3}.Do(func() error {
+		attempts++
+		return Permanent(errPermanent)
+	})
+	if err != errPermanent || attempts != 1 {
+		t.Errorf("err, attempts = %v, %d, want %v, 1", err, attempts, errPermanent)
+	}
+}
+
+func TestFixedDoesNotAllocate(t *testing.T) {
+	f := Fixed{Attempts: 5, Delay: time.Nanosecond}
+	worker := failTwice()
+	if n := testing.AllocsPerRun(100, func() { f.Do(worker) }); n != 0 {
+		t.Errorf("Fixed.Do made %v allocations, want 0", n)
+	}
+}
+
+func BenchmarkFixedDo(b *testing.B) {
+	f := Fixed{Attempts: 5}
+	worker := failTwice()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		f.Do(worker)
+	}
+}
+
+func BenchmarkRetry(b *testing.B) {
+	worker := failTwice()
+	timer := func() {}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Retry(worker, Counts(5), timer)
+	}
+}
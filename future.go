@@ -0,0 +1,47 @@
+package retry
+
+import "context"
+
+// Future is a retry loop running in the background.
+type Future struct {
+	done   chan struct{}
+	err    error
+	cancel context.CancelFunc
+}
+
+// Go runs a retry loop in a new goroutine, as with RetryPolicy, and returns a
+// Future for its result. The loop's context is derived from ctx and is
+// canceled either by Cancel or once the loop has finished. Because the Timer
+// is constructed with that context, canceling the loop also interrupts any
+// sleep made by a cancelable Timer.
+func Go(ctx context.Context, worker ContextWorker, limiter LimiterPolicy, timer TimerPolicy) *Future {
+	ctx, cancel := context.WithCancel(ctx)
+	f := &Future{
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+	go func() {
+		defer cancel()
+		f.err = RetryPolicy(ctx, worker, limiter, timer)
+		close(f.done)
+	}()
+	return f
+}
+
+// Done returns a channel that is closed when the loop has finished.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Err waits for the loop to finish and returns its error.
+func (f *Future) Err() error {
+	<-f.done
+	return f.err
+}
+
+// Cancel cancels the loop's context. No further attempts are made, but an
+// attempt in progress is only interrupted if the Worker honors its context.
+// Cancel does not wait for the loop to finish.
+func (f *Future) Cancel() {
+	f.cancel()
+}
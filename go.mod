@@ -1,3 +1,3 @@
 module github.com/colvin/retry
 
-go 1.16
+go 1.21
@@ -0,0 +1,42 @@
+package retry
+
+import (
+	"errors"
+	"sync"
+)
+
+// Group runs many Workers concurrently under the same Retryer, each in its
+// own retry loop with its own Limiter and Timer.
+type Group struct {
+	r    *Retryer
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewGroup returns a Group whose loops are made by r.
+func NewGroup(r *Retryer) *Group {
+	return &Group{r: r}
+}
+
+// Go runs a retry loop for worker in a new goroutine.
+func (g *Group) Go(worker Worker) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := g.r.Do(worker); err != nil {
+			g.mu.Lock()
+			g.errs = append(g.errs, err)
+			g.mu.Unlock()
+		}
+	}()
+}
+
+// Wait waits for every loop started by Go to finish. It returns the errors of
+// the loops that failed, joined using errors.Join, or nil if all succeeded.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return errors.Join(g.errs...)
+}
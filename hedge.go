@@ -0,0 +1,97 @@
+package retry
+
+import "context"
+
+// Hedge makes speculative concurrent attempts of the Worker to reduce tail
+// latency. The first attempt is made immediately. Each time the Timer returns
+// while no attempt has succeeded another attempt is started, provided fewer
+// than maxInFlight attempts are running. An attempt that fails also starts
+// another immediately. Hedge returns nil as soon as any attempt succeeds and
+// cancels the context passed to the others.
+//
+// The Limiter decides whether each attempt after the first is made, and so
+// bounds the total number of attempts. It is passed the error of the last
+// attempt to fail, or nil if none has failed yet. Once it refuses an attempt
+// no further attempts are started, so a Limiter that refuses a nil error,
+// such as OnErrors, stops hedging as soon as the Timer first returns.
+//
+// If every attempt fails the error of the last one to fail is returned. A
+// permanent error returns immediately. If ctx is done first, the error of the
+// last failed attempt, or the context's error if none has failed, is
+// returned without waiting for the outstanding attempts.
+//
+// The Timer runs in its own goroutine, which is not stopped when Hedge
+// returns; a Timer canceled by ctx should be used to avoid sleeping
+// needlessly.
+func Hedge(ctx context.Context, worker ContextWorker, maxInFlight int, limiter Limiter, spawnAfter Timer) error {
+	if maxInFlight < 1 {
+		maxInFlight = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan error, maxInFlight)
+	ticks := make(chan struct{})
+	var started, pending int
+	var waiting, exhausted bool
+	var err error
+	spawn := func() {
+		started++
+		pending++
+		attemptCtx := withAttempt(ctx, started)
+		go func() {
+			results <- worker(attemptCtx)
+		}()
+	}
+	// next starts a further attempt if the Limiter allows it.
+	next := func() {
+		if exhausted || pending >= maxInFlight {
+			return
+		}
+		if !limiter(err) {
+			exhausted = true
+			return
+		}
+		spawn()
+	}
+	// arm starts the Timer if another attempt could be started after it.
+	arm := func() {
+		if waiting || exhausted || pending >= maxInFlight {
+			return
+		}
+		waiting = true
+		go func() {
+			spawnAfter()
+			select {
+			case ticks <- struct{}{}:
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	spawn()
+	arm()
+	for pending > 0 {
+		select {
+		case err = <-results:
+			pending--
+			if err == nil {
+				return nil
+			}
+			if perm, ok := unwrapPermanent(err); ok {
+				return perm
+			}
+			next()
+		case <-ticks:
+			waiting = false
+			next()
+		case <-ctx.Done():
+			if err == nil {
+				err = ctx.Err()
+			}
+			return err
+		}
+		arm()
+	}
+	return err
+}
@@ -0,0 +1,145 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingWorker is a Worker for Hedge whose attempts block until released,
+// recording how many run at once.
+type blockingWorker struct {
+	release chan struct{}
+
+	mu       sync.Mutex
+	started  int
+	inFlight int
+	max      int
+}
+
+func newBlockingWorker() *blockingWorker {
+	return &blockingWorker{release: make(chan struct{})}
+}
+
+func (w *blockingWorker) work(ctx context.Context) error {
+	w.mu.Lock()
+	w.started++
+	w.inFlight++
+	if w.inFlight > w.max {
+		w.max = w.inFlight
+	}
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		w.inFlight--
+		w.mu.Unlock()
+	}()
+	select {
+	case <-w.release:
+	case <-ctx.Done():
+	}
+	return errTransient
+}
+
+// waitStarted waits until n attempts have started, and a little longer to
+// catch any attempts started beyond them.
+func (w *blockingWorker) waitStarted(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		w.mu.Lock()
+		started := w.started
+		w.mu.Unlock()
+		if started >= n {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d attempts started, want %d", started, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+}
+
+func TestHedgeCapsAttemptsInFlight(t *testing.T) {
+	w := newBlockingWorker()
+	done := make(chan error, 1)
+	go func() {
+		done <- Hedge(context.Background(), w.work, 2, Counts(6), func() {})
+	}()
+	w.waitStarted(t, 2)
+	close(w.release)
+
+	if err := <-done; err != errTransient {
+		t.Errorf("err = %v, want %v", err, errTransient)
+	}
+	if w.started != 6 {
+		t.Errorf("attempts = %d, want 6", w.started)
+	}
+	if w.max != 2 {
+		t.Errorf("attempts in flight = %d, want 2", w.max)
+	}
+}
+
+func TestHedgeLimiterBoundsAttempts(t *testing.T) {
+	w := newBlockingWorker()
+	done := make(chan error, 1)
+	go func() {
+		done <- Hedge(context.Background(), w.work, 10, Counts(3), func() {})
+	}()
+	w.waitStarted(t, 3)
+	close(w.release)
+
+	if err := <-done; err != errTransient {
+		t.Errorf("err = %v, want %v", err, errTransient)
+	}
+	if w.started != 3 {
+		t.Errorf("attempts = %d, want 3", w.started)
+	}
+}
+
+func TestHedgeFirstSuccessWins(t *testing.T) {
+	canceled := make(chan struct{}, 2)
+	worker := func(ctx context.Context) error {
+		if n, _ := AttemptFromContext(ctx); n == 3 {
+			return nil
+		}
+		<-ctx.Done()
+		canceled <- struct{}{}
+		return ctx.Err()
+	}
+	if err := Hedge(context.Background(), worker, 3, Forever(), func() {}); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-canceled:
+		case <-time.After(5 * time.Second):
+			t.Fatal("losing attempt was not canceled")
+		}
+	}
+}
+
+func TestHedgePermanentErrorReturnsEarly(t *testing.T) {
+	errFatal := errors.New("fatal")
+	canceled := make(chan struct{})
+	worker := func(ctx context.Context) error {
+		if n, _ := AttemptFromContext(ctx); n == 2 {
+			return Permanent(errFatal)
+		}
+		<-ctx.Done()
+		close(canceled)
+		return ctx.Err()
+	}
+	err := Hedge(context.Background(), worker, 2, Forever(), func() {})
+	if err != errFatal {
+		t.Errorf("err = %v, want %v", err, errFatal)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("outstanding attempt was not canceled")
+	}
+}
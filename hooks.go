@@ -0,0 +1,33 @@
+package retry
+
+import "time"
+
+// OnRetry registers a function to be called for each retry, that is every
+// attempt but the first. It is passed the failed attempt being retried and
+// the delay before the retry. The cancelable Timers of this package report
+// the delay they are about to sleep for, and the function is called before
+// the sleep; should the loop's context be done during it, OnGiveUp follows.
+// For other Timers the function is called once the Timer has returned,
+// immediately before the retry, and passed the time spent waiting.
+func OnRetry(fn func(a Attempt, delay time.Duration)) Option {
+	return func(r *Retryer) {
+		r.hooks.onRetry = append(r.hooks.onRetry, fn)
+	}
+}
+
+// OnSuccess registers a function to be called when an attempt succeeds. It is
+// passed the successful attempt, whose Err is nil.
+func OnSuccess(fn func(a Attempt)) Option {
+	return func(r *Retryer) {
+		r.hooks.onSuccess = append(r.hooks.onSuccess, fn)
+	}
+}
+
+// OnGiveUp registers a function to be called when a loop terminates without
+// success, whether because of the Limiter, a permanent error or the
+// cancellation of the loop's context. It is passed the final attempt.
+func OnGiveUp(fn func(a Attempt)) Option {
+	return func(r *Retryer) {
+		r.hooks.onGiveUp = append(r.hooks.onGiveUp, fn)
+	}
+}
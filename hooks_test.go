@@ -0,0 +1,36 @@
+package retry_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/colvin/retry"
+	"github.com/colvin/retry/retrytest"
+)
+
+func TestOnRetryBeforeSleep(t *testing.T) {
+	clock := retrytest.NewClock(time.Now())
+	defer clock.Install()()
+
+	var delays []time.Duration
+	var sleptBefore []int
+	retry.New(
+		retry.WithMaxAttempts(3),
+		retry.WithBackoff(time.Second, time.Minute),
+		retry.OnRetry(func(_ retry.Attempt, delay time.Duration) {
+			delays = append(delays, delay)
+			sleptBefore = append(sleptBefore, len(clock.Sleeps()))
+		}),
+	).Do(func() error {
+		return errors.New("fail")
+	})
+
+	if want := []time.Duration{time.Second, 2 * time.Second}; !reflect.DeepEqual(delays, want) {
+		t.Errorf("delays = %v, want %v", delays, want)
+	}
+	if want := []int{0, 1}; !reflect.DeepEqual(sleptBefore, want) {
+		t.Errorf("sleeps made before each hook = %v, want %v", sleptBefore, want)
+	}
+}
@@ -0,0 +1,90 @@
+package retry
+
+import (
+	"errors"
+	"io"
+)
+
+// errReaderClosed is returned by reads from a closed ResumableReader.
+var errReaderClosed = errors.New("retry: read from closed reader")
+
+// NewResumableReader returns a reader of the stream opened by open that
+// survives transient failures. When opening or reading the stream fails, the
+// Limiter decides whether to continue; if so, the Timer is called and the
+// stream is reopened at the offset following the last byte successfully
+// read, so a large download resumes rather than restarting. Permanent errors
+// are never retried. The Limiter and Timer are shared by every failure over
+// the life of the reader.
+//
+// open is passed zero to open the stream for the first time. Closing the
+// returned reader closes the currently open stream, if any.
+func NewResumableReader(open func(offset int64) (io.ReadCloser, error), limiter Limiter, timer Timer) io.ReadCloser {
+	return &resumableReader{
+		open:    open,
+		limiter: limiter,
+		timer:   timer,
+	}
+}
+
+type resumableReader struct {
+	open    func(offset int64) (io.ReadCloser, error)
+	limiter Limiter
+	timer   Timer
+	rc      io.ReadCloser
+	offset  int64
+	err     error
+}
+
+func (r *resumableReader) Read(p []byte) (int, error) {
+	for r.err == nil {
+		if r.rc == nil {
+			rc, err := r.open(r.offset)
+			if err != nil {
+				r.fail(err)
+				continue
+			}
+			r.rc = rc
+		}
+		n, err := r.rc.Read(p)
+		r.offset += int64(n)
+		if err == nil || err == io.EOF {
+			if err == io.EOF {
+				r.err = io.EOF
+			}
+			return n, err
+		}
+		r.rc.Close()
+		r.rc = nil
+		r.fail(err)
+		if n > 0 {
+			return n, nil
+		}
+	}
+	return 0, r.err
+}
+
+// fail decides whether to continue after err, waiting on the Timer if so and
+// recording err as the reader's error if not.
+func (r *resumableReader) fail(err error) {
+	if perm, ok := unwrapPermanent(err); ok {
+		r.err = perm
+		return
+	}
+	if !r.limiter(err) {
+		r.err = err
+		return
+	}
+	r.timer()
+}
+
+func (r *resumableReader) Close() error {
+	if r.err == nil {
+		r.err = errReaderClosed
+	}
+	if r.rc == nil {
+		return nil
+	}
+	err := r.rc.Close()
+	r.rc = nil
+	return err
+}
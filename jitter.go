@@ -0,0 +1,109 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Jitter is a strategy for randomizing backoff delays so that many clients
+// failing at the same moment do not retry in lockstep. The strategies are
+// those described in the AWS Architecture Blog post "Exponential Backoff And
+// Jitter".
+type Jitter int
+
+const (
+	// NoJitter sleeps for exactly the computed backoff.
+	NoJitter Jitter = iota
+	// FullJitter sleeps for a random duration between zero and the computed
+	// backoff.
+	FullJitter
+	// EqualJitter sleeps for half of the computed backoff plus a random
+	// duration up to the other half.
+	EqualJitter
+	// DecorrelatedJitter sleeps for a random duration between the base and
+	// three times the previous sleep, limited by the ceiling. It does not
+	// follow the doubling of the computed backoff.
+	DecorrelatedJitter
+)
+
+// JitteredBackoff returns a Timer that sleeps like MultiplicativeBackoff but
+// randomizes each sleep using the given Jitter strategy. Randomness is drawn
+// from Rand.
+func JitteredBackoff(base time.Duration, ceil time.Duration, jitter Jitter) Timer {
+	return sleeper(jittered(exponential(base, 2, ceil), base, ceil, jitter))
+}
+
+// CancelableJitteredBackoff is the same as JitteredBackoff but can be canceled
+// using a context.
+func CancelableJitteredBackoff(ctx context.Context, base time.Duration, ceil time.Duration, jitter Jitter) Timer {
+	return cancelableSleeper(ctx, jittered(exponential(base, 2, ceil), base, ceil, jitter))
+}
+
+// jittered returns a function computing successive delays by applying the
+// jitter strategy to those computed by next. The base and ceiling are those
+// of next, and are used by DecorrelatedJitter in its place.
+func jittered(next func() time.Duration, base time.Duration, ceil time.Duration, jitter Jitter) func() time.Duration {
+	prev := base
+	return func() time.Duration {
+		dur := next()
+		var d time.Duration
+		switch jitter {
+		case FullJitter:
+			d = randDuration(0, dur)
+		case EqualJitter:
+			d = dur/2 + randDuration(0, dur-dur/2)
+		case DecorrelatedJitter:
+			prev = randDuration(base, prev*3)
+			if prev > ceil {
+				prev = ceil
+			}
+			d = prev
+		default:
+			d = dur
+		}
+		return d
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler, encoding the strategy as
+// "none", "full", "equal" or "decorrelated".
+func (j Jitter) MarshalText() ([]byte, error) {
+	switch j {
+	case NoJitter:
+		return []byte("none"), nil
+	case FullJitter:
+		return []byte("full"), nil
+	case EqualJitter:
+		return []byte("equal"), nil
+	case DecorrelatedJitter:
+		return []byte("decorrelated"), nil
+	}
+	return nil, fmt.Errorf("retry: unknown jitter %d", int(j))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, decoding the names
+// produced by MarshalText. The empty string decodes as NoJitter.
+func (j *Jitter) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "", "none":
+		*j = NoJitter
+	case "full":
+		*j = FullJitter
+	case "equal":
+		*j = EqualJitter
+	case "decorrelated":
+		*j = DecorrelatedJitter
+	default:
+		return fmt.Errorf("retry: unknown jitter %q", text)
+	}
+	return nil
+}
+
+// randDuration returns a random duration in the half-open interval [lo,hi).
+func randDuration(lo time.Duration, hi time.Duration) time.Duration {
+	if hi <= lo {
+		return lo
+	}
+	return lo + time.Duration(Rand()*float64(hi-lo))
+}
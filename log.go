@@ -0,0 +1,32 @@
+package retry
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// WithLogger logs each failed attempt of the Retryer's loops to logger. An
+// attempt that is retried is logged at the Info level, and the final attempt
+// of a loop that gives up is logged at the Warn level. Each record has the
+// attributes attempt, error and elapsed; records of retries also have delay,
+// the delay before the retry. As described by OnRetry, with the cancelable
+// Timers of this package a retry is logged before the wait, with the delay
+// the Timer computed.
+func WithLogger(logger *slog.Logger) Option {
+	return func(r *Retryer) {
+		OnRetry(func(a Attempt, delay time.Duration) {
+			logger.LogAttrs(context.Background(), slog.LevelInfo, "retrying failed attempt",
+				slog.Int("attempt", a.Number),
+				slog.Any("error", a.Err),
+				slog.Duration("delay", delay),
+				slog.Duration("elapsed", a.Elapsed))
+		})(r)
+		OnGiveUp(func(a Attempt) {
+			logger.LogAttrs(context.Background(), slog.LevelWarn, "giving up after failed attempt",
+				slog.Int("attempt", a.Number),
+				slog.Any("error", a.Err),
+				slog.Duration("elapsed", a.Elapsed))
+		})(r)
+	}
+}
@@ -0,0 +1,47 @@
+package retry_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/colvin/retry"
+	"github.com/colvin/retry/retrytest"
+)
+
+func TestWithLoggerLogsComputedDelay(t *testing.T) {
+	clock := retrytest.NewClock(time.Now())
+	defer clock.Install()()
+
+	var buf bytes.Buffer
+	var sleepsAtLog int
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == "delay" {
+				sleepsAtLog = len(clock.Sleeps())
+			}
+			return a
+		},
+	}))
+	retry.New(
+		retry.WithMaxAttempts(2),
+		retry.WithBackoff(3*time.Second, time.Minute),
+		retry.WithLogger(logger),
+	).Do(func() error {
+		return errors.New("fail")
+	})
+
+	out := buf.String()
+	if !strings.Contains(out, `msg="retrying failed attempt" attempt=1 error=fail delay=3s`) {
+		t.Errorf("no retry record with the computed delay in:\n%s", out)
+	}
+	if sleepsAtLog != 0 {
+		t.Errorf("retry logged after %d sleeps, want before the sleep", sleepsAtLog)
+	}
+	if !strings.Contains(out, `level=WARN msg="giving up after failed attempt" attempt=2`) {
+		t.Errorf("no give-up record in:\n%s", out)
+	}
+}
@@ -0,0 +1,149 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// loop is the configuration of a single retry loop.
+type loop struct {
+	limiter        LimiterFunc
+	timer          TimerFunc
+	hooks          hooks
+	cancelError    bool
+	exhaustedError bool
+	// state is shared with Timers constructed for the loop, if any.
+	state *timerState
+	// contextFree is set if the Worker ignores its context, which then
+	// need not record the attempt number.
+	contextFree bool
+	// reason is the reason the loop terminated without success.
+	reason StopReason
+}
+
+// hooks are the callbacks made during a loop.
+type hooks struct {
+	onRetry   []func(Attempt, time.Duration)
+	onSuccess []func(Attempt)
+	onGiveUp  []func(Attempt)
+	onEvent   []func(AttemptEvent)
+}
+
+// run implements the retry loop shared by the Retry functions.
+func run(ctx context.Context, worker ContextWorker, limiter LimiterFunc, timer TimerFunc) error {
+	l := loop{limiter: limiter, timer: timer}
+	return l.run(ctx, worker)
+}
+
+// run runs the loop for the given Worker. No further attempts are made once
+// ctx is done or its deadline has passed, or once the Worker returns a
+// permanent error.
+func (l *loop) run(ctx context.Context, worker ContextWorker) error {
+	a := Attempt{Start: now()}
+	for {
+		a.Number++
+		l.hooks.event(a, EventStart, 0)
+		start := now()
+		actx := ctx
+		if !l.contextFree {
+			actx = withAttempt(ctx, a.Number)
+		}
+		a.Err = worker(actx)
+		a.Duration = since(start)
+		a.Elapsed = since(a.Start)
+		if a.Err == nil {
+			l.hooks.success(a)
+			return nil
+		}
+		l.hooks.event(a, EventFailure, 0)
+		if err, ok := unwrapPermanent(a.Err); ok {
+			l.hooks.giveUp(a)
+			return l.exhausted(a, StopPermanent, err)
+		}
+		if expired(ctx) || !l.limiter(a) {
+			// The reason is settled before the hooks are called, so that
+			// the context expiring during them does not change it.
+			reason, err := StopLimiter, a.Err
+			if expired(ctx) {
+				reason, err = StopCanceled, l.canceled(ctx, a.Err)
+			}
+			l.hooks.giveUp(a)
+			return l.exhausted(a, reason, err)
+		}
+		// The retry hooks are called as the Timer announces its delay or,
+		// if it does not, once it has returned.
+		var announce func(time.Duration)
+		var announced bool
+		if l.state != nil && len(l.hooks.onRetry) > 0 {
+			failed := a
+			announce = func(d time.Duration) {
+				announced = true
+				l.hooks.retry(failed, d)
+			}
+		}
+		start = now()
+		if l.state == nil {
+			// A Timer constructed by the caller cannot be told of the
+			// delay specified by the error, so the loop sleeps for it.
+			if after, ok := RetryAfter(a.Err); ok {
+				sleepContext(ctx, after)
+			} else {
+				l.timer(a)
+			}
+		} else {
+			l.state.begin(a.Err, announce)
+			l.timer(a)
+			l.state.remainder(ctx, since(start))
+			l.state.end()
+		}
+		delay := since(start)
+		l.hooks.event(a, EventSleep, delay)
+		if expired(ctx) {
+			l.hooks.giveUp(a)
+			return l.exhausted(a, StopCanceled, l.canceled(ctx, a.Err))
+		}
+		if !announced {
+			l.hooks.retry(a, delay)
+		}
+		a.Delay = delay
+	}
+}
+
+func (h *hooks) retry(a Attempt, delay time.Duration) {
+	for _, fn := range h.onRetry {
+		fn(a, delay)
+	}
+}
+
+func (h *hooks) success(a Attempt) {
+	for _, fn := range h.onSuccess {
+		fn(a)
+	}
+	h.event(a, EventSuccess, 0)
+}
+
+func (h *hooks) giveUp(a Attempt) {
+	for _, fn := range h.onGiveUp {
+		fn(a)
+	}
+	h.event(a, EventGiveUp, 0)
+}
+
+func (h *hooks) event(a Attempt, kind EventKind, delay time.Duration) {
+	if len(h.onEvent) == 0 {
+		return
+	}
+	e := AttemptEvent{
+		Kind:    kind,
+		Attempt: a.Number,
+		Delay:   delay,
+		Start:   a.Start,
+		Time:    now(),
+	}
+	if kind == EventFailure || kind == EventGiveUp {
+		e.Err = a.Err
+	}
+	for _, fn := range h.onEvent {
+		fn(e)
+	}
+}
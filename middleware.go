@@ -0,0 +1,47 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Middleware decorates a Worker with a cross-cutting concern such as
+// logging, metrics or fault handling. Recover is a Middleware, as are the
+// Worker methods of Budget, Adaptive and Breaker; Chaos and Timeout adapt the
+// other decorators of this package.
+type Middleware func(Worker) Worker
+
+// Chain returns a Middleware applying each of the given Middleware in turn,
+// the first being outermost: an attempt passes through them in the order
+// given on its way to the Worker and in the reverse order on its way back.
+func Chain(middleware ...Middleware) Middleware {
+	return func(worker Worker) Worker {
+		for i := len(middleware) - 1; i >= 0; i-- {
+			worker = middleware[i](worker)
+		}
+		return worker
+	}
+}
+
+// Chaos returns a Middleware injecting failures as with WithChaos.
+func Chaos(failureRate float64, chaosErr error) Middleware {
+	return func(worker Worker) Worker {
+		return WithChaos(worker, failureRate, chaosErr)
+	}
+}
+
+// Timeout returns a Middleware bounding each attempt to the given duration
+// as with WithTimeout. As the Worker is not passed a context, an attempt
+// that times out is always abandoned rather than canceled. Attempts run in
+// their own goroutines, so a Recover meant to catch their panics must be
+// placed after Timeout in a Chain.
+func Timeout(timeout time.Duration) Middleware {
+	return func(worker Worker) Worker {
+		w := WithTimeout(func(_ context.Context) error {
+			return worker()
+		}, timeout)
+		return func() error {
+			return w(context.Background())
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package retry
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+// TransientNetError returns a Limiter that allows a further attempt only if
+// the error appears to be a transient network failure: a timeout, a
+// connection reset or refused by the peer, or a temporary DNS failure. Every
+// other error terminates the loop. It is typically combined with a bounding
+// Limiter, as in All(TransientNetError(), Counts(5)).
+func TransientNetError() Limiter {
+	return isTransientNetError
+}
+
+func isTransientNetError(err error) bool {
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTemporary || dnsErr.IsTimeout
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,185 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Backoff is a retry policy that decides whether to make a further attempt
+// and how long to wait before it in a single computation, for policies in
+// which the two are not independent. Next is called with the error of each
+// failed attempt and returns the delay before the next attempt, or false to
+// terminate the loop. Like a Limiter and Timer, a Backoff typically tracks a
+// single loop.
+//
+// A Limiter and Timer cannot be converted into a Backoff, as the Timer sleeps
+// rather than return its delay; the built-in policies have Backoff forms,
+// such as ExponentialBackoffNext and CountsNext, for use in its place.
+type Backoff interface {
+	Next(err error) (time.Duration, bool)
+}
+
+// BackoffFunc is a function implementing Backoff.
+type BackoffFunc func(err error) (time.Duration, bool)
+
+// Next implements Backoff.
+func (f BackoffFunc) Next(err error) (time.Duration, bool) {
+	return f(err)
+}
+
+// RetryBackoff is the same as RetryContext but uses a Backoff in place of a
+// Limiter and Timer. The sleeps are canceled by ctx.
+func RetryBackoff(ctx context.Context, worker ContextWorker, b Backoff) error {
+	limiter, timer := PairFromBackoff(ctx, b)
+	return RetryContext(ctx, worker, limiter, timer)
+}
+
+// PairFromBackoff adapts a Backoff for use where a Limiter and Timer are
+// expected. The Limiter calls Next and the Timer sleeps for the delay it
+// returned, the sleep being canceled by ctx.
+func PairFromBackoff(ctx context.Context, b Backoff) (Limiter, Timer) {
+	state := timerStateFrom(ctx)
+	var delay time.Duration
+	limiter := func(err error) bool {
+		d, ok := b.Next(err)
+		delay = d
+		return ok
+	}
+	timer := func() {
+		sleepContext(ctx, state.delay(delay))
+	}
+	return limiter, timer
+}
+
+// ConfigBackoff returns a Backoff implementing the policy described by cfg,
+// or an error describing why cfg is invalid. As with MaxElapsed, the elapsed
+// time is measured from the first call to Next.
+func ConfigBackoff(cfg Config) (Backoff, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	next, _ := cfg.delays()
+	delay := next()
+	var start time.Time
+	var n int
+	return BackoffFunc(func(_ error) (time.Duration, bool) {
+		if start.IsZero() {
+			start = now()
+		}
+		n++
+		if cfg.MaxAttempts > 0 && n >= cfg.MaxAttempts {
+			return 0, false
+		}
+		if cfg.MaxElapsed > 0 && since(start) >= time.Duration(cfg.MaxElapsed) {
+			return 0, false
+		}
+		return delay(), true
+	}), nil
+}
+
+// ExponentialBackoffNext returns a Backoff that always allows a further
+// attempt, with the delays of ExponentialBackoff. Use CountsNext or
+// MaxElapsedNext to bound it.
+func ExponentialBackoffNext(base time.Duration, factor float64, ceil time.Duration) Backoff {
+	return delaysBackoff(exponential(base, factor, ceil))
+}
+
+// FibonacciBackoffNext returns a Backoff that always allows a further
+// attempt, with the delays of FibonacciBackoff.
+func FibonacciBackoffNext(base time.Duration, ceil time.Duration) Backoff {
+	return delaysBackoff(fibonacci(base, ceil))
+}
+
+// LinearBackoffNext returns a Backoff that always allows a further attempt,
+// with the delays of LinearBackoff.
+func LinearBackoffNext(base time.Duration, increment time.Duration, ceil time.Duration) Backoff {
+	return delaysBackoff(linear(base, increment, ceil))
+}
+
+// JitteredBackoffNext returns a Backoff that always allows a further attempt,
+// with the delays of JitteredBackoff.
+func JitteredBackoffNext(base time.Duration, ceil time.Duration, jitter Jitter) Backoff {
+	return delaysBackoff(jittered(exponential(base, 2, ceil), base, ceil, jitter))
+}
+
+// CountsNext returns a Backoff that terminates the loop under the same
+// condition as Counts, and otherwise returns the delay computed by b. Once
+// the loop is terminated, b is no longer called.
+func CountsNext(max int, b Backoff) Backoff {
+	return limitedBackoff(Counts(max), b)
+}
+
+// MaxElapsedNext returns a Backoff that terminates the loop under the same
+// condition as MaxElapsed, and otherwise returns the delay computed by b.
+func MaxElapsedNext(max time.Duration, b Backoff) Backoff {
+	return limitedBackoff(MaxElapsed(max), b)
+}
+
+// delaysBackoff returns a Backoff that always allows a further attempt, with
+// the delays computed by next.
+func delaysBackoff(next func() time.Duration) Backoff {
+	return BackoffFunc(func(_ error) (time.Duration, bool) {
+		return next(), true
+	})
+}
+
+// limitedBackoff returns a Backoff that terminates the loop when either
+// limiter or b does, the delay being that computed by b.
+func limitedBackoff(limiter Limiter, b Backoff) Backoff {
+	return BackoffFunc(func(err error) (time.Duration, bool) {
+		if !limiter(err) {
+			return 0, false
+		}
+		return b.Next(err)
+	})
+}
+
+// stopBackOff is the delay by which the policies of libraries such as
+// github.com/cenkalti/backoff signal that no further attempt should be made.
+const stopBackOff time.Duration = -1
+
+// FromBackOff adapts a policy of a library such as
+// github.com/cenkalti/backoff, whose NextBackOff method returns the delay
+// before the next attempt or -1 to stop, for use where a Backoff is expected.
+// The policy should be reset before the loop if it has been used before.
+func FromBackOff(b interface{ NextBackOff() time.Duration }) Backoff {
+	return BackoffFunc(func(_ error) (time.Duration, bool) {
+		d := b.NextBackOff()
+		if d == stopBackOff {
+			return 0, false
+		}
+		return d, true
+	})
+}
+
+// BackOffAdapter adapts Backoffs to the interface of libraries such as
+// github.com/cenkalti/backoff, so that the policies of this package can be
+// used with them. It is returned by ToBackOff.
+type BackOffAdapter struct {
+	newBackoff func() Backoff
+	b          Backoff
+}
+
+// ToBackOff returns a BackOffAdapter for the Backoffs returned by
+// newBackoff, which is called whenever the adapter is reset.
+func ToBackOff(newBackoff func() Backoff) *BackOffAdapter {
+	return &BackOffAdapter{
+		newBackoff: newBackoff,
+		b:          newBackoff(),
+	}
+}
+
+// NextBackOff returns the delay before the next attempt or -1 to stop. The
+// Backoff is passed a nil error, as the interface does not convey it.
+func (a *BackOffAdapter) NextBackOff() time.Duration {
+	d, ok := a.b.Next(nil)
+	if !ok {
+		return stopBackOff
+	}
+	return d
+}
+
+// Reset replaces the Backoff with a new one.
+func (a *BackOffAdapter) Reset() {
+	a.b = a.newBackoff()
+}
@@ -0,0 +1,107 @@
+package retry_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/colvin/retry"
+	"github.com/colvin/retry/retrytest"
+)
+
+// delays calls Next until it terminates the loop or n delays are returned.
+func delays(b retry.Backoff, n int) []time.Duration {
+	var ds []time.Duration
+	for len(ds) < n {
+		d, ok := b.Next(errors.New("fail"))
+		if !ok {
+			break
+		}
+		ds = append(ds, d)
+	}
+	return ds
+}
+
+func TestBackoffNext(t *testing.T) {
+	tests := []struct {
+		name string
+		b    retry.Backoff
+		want []time.Duration
+	}{
+		{
+			name: "exponential",
+			b:    retry.ExponentialBackoffNext(time.Second, 2, 5*time.Second),
+			want: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second},
+		},
+		{
+			name: "fibonacci",
+			b:    retry.FibonacciBackoffNext(time.Second, 4*time.Second),
+			want: []time.Duration{time.Second, time.Second, 2 * time.Second, 3 * time.Second, 4 * time.Second},
+		},
+		{
+			name: "linear",
+			b:    retry.LinearBackoffNext(time.Second, 2*time.Second, 6*time.Second),
+			want: []time.Duration{time.Second, 3 * time.Second, 5 * time.Second, 6 * time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := delays(tt.b, 10); !reflect.DeepEqual(got[:min(len(got), len(tt.want))], tt.want) {
+				t.Errorf("delays = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountsNext(t *testing.T) {
+	b := retry.CountsNext(3, retry.LinearBackoffNext(time.Second, time.Second, time.Minute))
+	want := []time.Duration{time.Second, 2 * time.Second}
+	if got := delays(b, 10); !reflect.DeepEqual(got, want) {
+		t.Errorf("delays = %v, want %v", got, want)
+	}
+}
+
+func TestMaxElapsedNext(t *testing.T) {
+	clock := retrytest.NewClock(time.Now())
+	defer clock.Install()()
+
+	b := retry.MaxElapsedNext(time.Minute, retry.ExponentialBackoffNext(time.Second, 2, time.Minute))
+	if d, ok := b.Next(nil); !ok || d != time.Second {
+		t.Fatalf("Next = %v, %v, want 1s, true", d, ok)
+	}
+	clock.Advance(time.Minute)
+	if _, ok := b.Next(nil); ok {
+		t.Fatal("Next allowed an attempt after the maximum elapsed time")
+	}
+}
+
+func TestJitteredBackoffNext(t *testing.T) {
+	b := retry.JitteredBackoffNext(time.Second, 8*time.Second, retry.FullJitter)
+	for i, d := range delays(b, 5) {
+		if d < 0 || d > 8*time.Second {
+			t.Errorf("delay %d = %v, want within [0, 8s]", i, d)
+		}
+	}
+}
+
+func TestConfigBackoffMaxElapsedStartsAtFirstNext(t *testing.T) {
+	clock := retrytest.NewClock(time.Now())
+	defer clock.Install()()
+
+	b, err := retry.ConfigBackoff(retry.Config{
+		MaxElapsed: retry.Duration(time.Minute),
+		Base:       retry.Duration(time.Second),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Hour)
+	if _, ok := b.Next(nil); !ok {
+		t.Fatal("Next refused the first retry of a loop started after construction")
+	}
+	clock.Advance(time.Minute)
+	if _, ok := b.Next(nil); ok {
+		t.Fatal("Next allowed an attempt after the maximum elapsed time")
+	}
+}
@@ -0,0 +1,41 @@
+package retry
+
+// permanentError marks an error as one that should not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent wraps err to indicate that the Worker should not be retried. When
+// a Worker returns a permanent error the loop terminates immediately without
+// consulting the Limiter and the wrapped error is returned to the caller.
+// Permanent returns nil if err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether any error in err's chain was marked using
+// Permanent.
+func IsPermanent(err error) bool {
+	_, ok := find[*permanentError](err)
+	return ok
+}
+
+// unwrapPermanent reports whether err is permanent, removing the outermost
+// mark if err itself is the mark.
+func unwrapPermanent(err error) (error, bool) {
+	if p, ok := err.(*permanentError); ok {
+		return p.err, true
+	}
+	return err, IsPermanent(err)
+}
@@ -0,0 +1,96 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// LimiterPolicy is an immutable description of a Limiter. Many Limiters, such
+// as the one returned by Counts, hold state for the loop they are used in and
+// must not be shared between loops. A LimiterPolicy instead constructs a new
+// Limiter for every loop, so it can be stored and used concurrently.
+//
+// Constructors of stateless Limiters, such as Forever and Once, are
+// themselves LimiterPolicies.
+type LimiterPolicy func() Limiter
+
+// TimerPolicy is an immutable description of a Timer. Like a LimiterPolicy it
+// constructs a new Timer for every loop. It is passed the context of the loop
+// so that the Timer can be canceled by it.
+type TimerPolicy func(context.Context) Timer
+
+// RetryPolicy is the same as RetryContext but constructs the Limiter and
+// Timer of the loop from the given policies.
+func RetryPolicy(ctx context.Context, worker ContextWorker, limiter LimiterPolicy, timer TimerPolicy) error {
+	tctx, state := withTimerState(ctx)
+	l := loop{
+		limiter: AttemptLimiter(limiter()),
+		timer:   AttemptTimer(timer(tctx)),
+		state:   state,
+	}
+	return l.run(ctx, worker)
+}
+
+// CountsPolicy returns a LimiterPolicy for Counts.
+func CountsPolicy(max int) LimiterPolicy {
+	return func() Limiter {
+		return Counts(max)
+	}
+}
+
+// MaxElapsedPolicy returns a LimiterPolicy for MaxElapsed.
+func MaxElapsedPolicy(max time.Duration) LimiterPolicy {
+	return func() Limiter {
+		return MaxElapsed(max)
+	}
+}
+
+// SleepPolicy returns a TimerPolicy for CancelableSleep.
+func SleepPolicy(dur time.Duration) TimerPolicy {
+	return func(ctx context.Context) Timer {
+		return CancelableSleep(ctx, dur)
+	}
+}
+
+// BackoffPolicy returns a TimerPolicy for CancelableMultiplicativeBackoff.
+func BackoffPolicy(base time.Duration, ceil time.Duration) TimerPolicy {
+	return func(ctx context.Context) Timer {
+		return CancelableMultiplicativeBackoff(ctx, base, ceil)
+	}
+}
+
+// ExponentialBackoffPolicy returns a TimerPolicy for
+// CancelableExponentialBackoff.
+func ExponentialBackoffPolicy(base time.Duration, factor float64, ceil time.Duration) TimerPolicy {
+	return func(ctx context.Context) Timer {
+		return CancelableExponentialBackoff(ctx, base, factor, ceil)
+	}
+}
+
+// DeadlineAwareBackoffPolicy returns a TimerPolicy for DeadlineAwareBackoff.
+func DeadlineAwareBackoffPolicy(base time.Duration, ceil time.Duration) TimerPolicy {
+	return func(ctx context.Context) Timer {
+		return DeadlineAwareBackoff(ctx, base, ceil)
+	}
+}
+
+// JitteredBackoffPolicy returns a TimerPolicy for CancelableJitteredBackoff.
+func JitteredBackoffPolicy(base time.Duration, ceil time.Duration, jitter Jitter) TimerPolicy {
+	return func(ctx context.Context) Timer {
+		return CancelableJitteredBackoff(ctx, base, ceil, jitter)
+	}
+}
+
+// FibonacciBackoffPolicy returns a TimerPolicy for CancelableFibonacciBackoff.
+func FibonacciBackoffPolicy(base time.Duration, ceil time.Duration) TimerPolicy {
+	return func(ctx context.Context) Timer {
+		return CancelableFibonacciBackoff(ctx, base, ceil)
+	}
+}
+
+// LinearBackoffPolicy returns a TimerPolicy for CancelableLinearBackoff.
+func LinearBackoffPolicy(base time.Duration, increment time.Duration, ceil time.Duration) TimerPolicy {
+	return func(ctx context.Context) Timer {
+		return CancelableLinearBackoff(ctx, base, increment, ceil)
+	}
+}
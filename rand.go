@@ -0,0 +1,9 @@
+package retry
+
+import "math/rand"
+
+// Rand returns a pseudo-random number in the half-open interval [0.0,1.0). It
+// is the source of randomness for everything in this package and may be
+// replaced with a deterministic source, for example in tests. It must be safe
+// for concurrent use.
+var Rand func() float64 = rand.Float64
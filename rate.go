@@ -0,0 +1,33 @@
+package retry
+
+import "context"
+
+// Waiter is a rate limiter that blocks until an event is permitted. It is
+// satisfied by *rate.Limiter from golang.org/x/time/rate.
+type Waiter interface {
+	Wait(ctx context.Context) error
+}
+
+// RateTimer returns a Timer that waits on w, so that retries are paced by the
+// same rate limiter as other outbound calls. The wait may be canceled using a
+// context. If w fails to wait, as *rate.Limiter does at once when the wait
+// would pass the context's deadline, the Timer sleeps until the deadline
+// rather than let the retry go unpaced; without a deadline, for example when
+// the burst is zero, it returns immediately. A delay specified by the error of
+// the failed attempt is waited out in full, after waiting on w.
+func RateTimer(ctx context.Context, w Waiter) Timer {
+	state := timerStateFrom(ctx)
+	return func() {
+		start := now()
+		if err := w.Wait(ctx); err != nil {
+			if deadline, ok := ctx.Deadline(); ok {
+				sleepContext(ctx, until(deadline))
+			}
+		}
+		if after, ok := state.take(); ok {
+			if d := after - since(start); d > 0 {
+				sleepContext(ctx, d)
+			}
+		}
+	}
+}
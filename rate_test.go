@@ -0,0 +1,49 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/colvin/retry"
+	"github.com/colvin/retry/retrytest"
+)
+
+// failingWaiter is a Waiter that always fails to wait.
+type failingWaiter struct {
+	waits int
+}
+
+func (w *failingWaiter) Wait(_ context.Context) error {
+	w.waits++
+	return errors.New("rate: Wait would exceed context deadline")
+}
+
+func TestRateTimerSleepsUntilDeadlineWhenWaitFails(t *testing.T) {
+	clock := retrytest.NewClock(time.Now())
+	defer clock.Install()()
+
+	ctx, cancel := context.WithDeadline(context.Background(), clock.Now().Add(time.Minute))
+	defer cancel()
+	w := &failingWaiter{}
+	retry.RateTimer(ctx, w)()
+	if w.waits != 1 {
+		t.Errorf("waits = %d, want 1", w.waits)
+	}
+	want := []time.Duration{time.Minute}
+	if got := clock.Sleeps(); !reflect.DeepEqual(got, want) {
+		t.Errorf("sleeps = %v, want %v", got, want)
+	}
+}
+
+func TestRateTimerReturnsWhenWaitFailsWithoutDeadline(t *testing.T) {
+	clock := retrytest.NewClock(time.Now())
+	defer clock.Install()()
+
+	retry.RateTimer(context.Background(), &failingWaiter{})()
+	if got := clock.Sleeps(); len(got) != 0 {
+		t.Errorf("sleeps = %v, want none", got)
+	}
+}
@@ -0,0 +1,41 @@
+package retry
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is returned by a Worker made by Recover when the interior
+// Worker panics.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("retry: worker panicked: %v", e.Value)
+}
+
+// Unwrap returns the value passed to panic if it is an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// Recover returns a Worker that converts a panic in worker into a
+// *PanicError, so that the Limiter can decide whether to retry it rather than
+// the panic taking down the loop and its caller.
+func Recover(worker Worker) Worker {
+	return func() (err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				err = &PanicError{Value: v, Stack: debug.Stack()}
+			}
+		}()
+		return worker()
+	}
+}
@@ -0,0 +1,86 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// BackoffRegistry holds independent Backoff state for many keys, such as
+// hosts or tenants, that persists across loops. A loop retrying a call to a
+// key uses the Backoff of that key, so a key that has been failing continues
+// from its current backoff while other keys are unaffected. Callers should
+// Reset a key once a call to it succeeds.
+//
+// State for a key is created on first use, and is discarded once the key has
+// been idle for longer than the registry's idle interval. It is safe for
+// concurrent use; calls to the Backoff of the same key are serialized, but
+// as a Backoff only computes delays, the sleeps of loops using it, made by
+// RetryBackoff or the Timer of PairFromBackoff, are not.
+type BackoffRegistry struct {
+	mu         sync.Mutex
+	newBackoff func() Backoff
+	idle       time.Duration
+	entries    map[string]*backoffEntry
+}
+
+type backoffEntry struct {
+	mu      sync.Mutex
+	backoff Backoff
+	used    time.Time
+}
+
+// NewBackoffRegistry returns a BackoffRegistry that constructs the state of
+// each key using newBackoff and discards it after idle.
+func NewBackoffRegistry(newBackoff func() Backoff, idle time.Duration) *BackoffRegistry {
+	return &BackoffRegistry{
+		newBackoff: newBackoff,
+		idle:       idle,
+		entries:    make(map[string]*backoffEntry),
+	}
+}
+
+// Backoff returns a Backoff using the state of the given key, for use with
+// RetryBackoff or PairFromBackoff.
+func (r *BackoffRegistry) Backoff(key string) Backoff {
+	return BackoffFunc(func(err error) (time.Duration, bool) {
+		e := r.entry(key)
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		return e.backoff.Next(err)
+	})
+}
+
+// Reset discards the state of the given key, so that its next loop starts
+// afresh.
+func (r *BackoffRegistry) Reset(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.entries, key)
+}
+
+// Prune discards the state of every key that has been idle for longer than
+// the registry's idle interval. Idle state is also discarded when its key is
+// next used, so calling Prune is only necessary to reclaim memory.
+func (r *BackoffRegistry) Prune() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, e := range r.entries {
+		if since(e.used) > r.idle {
+			delete(r.entries, key)
+		}
+	}
+}
+
+// entry returns the state of the given key, creating it if necessary, and
+// marks it used.
+func (r *BackoffRegistry) entry(key string) *backoffEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.entries[key]
+	if !ok || since(e.used) > r.idle {
+		e = &backoffEntry{backoff: r.newBackoff()}
+		r.entries[key] = e
+	}
+	e.used = now()
+	return e
+}
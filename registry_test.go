@@ -0,0 +1,51 @@
+package retry_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/colvin/retry"
+)
+
+func TestBackoffRegistrySleepsOutsideLock(t *testing.T) {
+	var calls int
+	reg := retry.NewBackoffRegistry(func() retry.Backoff {
+		return retry.BackoffFunc(func(error) (time.Duration, bool) {
+			calls++
+			return time.Hour, true
+		})
+	}, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	limiter, timer := retry.PairFromBackoff(ctx, reg.Backoff("a"))
+	limiter(nil)
+	slept := make(chan struct{})
+	go func() {
+		timer()
+		close(slept)
+	}()
+
+	// Another loop of the same key is not blocked by the sleep.
+	next := make(chan struct{})
+	go func() {
+		reg.Backoff("a").Next(nil)
+		close(next)
+	}()
+	select {
+	case <-next:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Next blocked by another loop's sleep")
+	}
+
+	// The sleep is canceled by the loop's own context.
+	cancel()
+	select {
+	case <-slept:
+	case <-time.After(5 * time.Second):
+		t.Fatal("sleep not canceled by the loop's context")
+	}
+	if calls != 2 {
+		t.Errorf("Next called %d times, want 2", calls)
+	}
+}
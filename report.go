@@ -0,0 +1,56 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Report describes the execution of a retry loop.
+type Report struct {
+	// Attempts is the number of attempts made.
+	Attempts int
+	// Sleep is the total time spent waiting between attempts.
+	Sleep time.Duration
+	// Elapsed is the total time taken by the loop.
+	Elapsed time.Duration
+	// Records describes each attempt, in order.
+	Records []AttemptRecord
+}
+
+// AttemptRecord describes a single attempt of a retry loop.
+type AttemptRecord struct {
+	// Err is the error returned by the attempt, if any.
+	Err error
+	// Duration is the time taken by the attempt.
+	Duration time.Duration
+}
+
+// RetryReport is the same as Retry but also returns a Report describing the
+// execution of the loop, whether or not it succeeded.
+func RetryReport(worker Worker, limiter Limiter, timer Timer) (Report, error) {
+	var report Report
+	l := loop{
+		limiter: AttemptLimiter(limiter),
+		timer:   AttemptTimer(timer),
+		hooks: hooks{
+			onRetry: []func(Attempt, time.Duration){
+				func(_ Attempt, delay time.Duration) {
+					report.Sleep += delay
+				},
+			},
+		},
+	}
+	start := now()
+	err := l.run(context.Background(), func(_ context.Context) error {
+		attemptStart := now()
+		err := worker()
+		report.Records = append(report.Records, AttemptRecord{
+			Err:      err,
+			Duration: since(attemptStart),
+		})
+		return err
+	})
+	report.Attempts = len(report.Records)
+	report.Elapsed = since(start)
+	return report, err
+}
@@ -0,0 +1,87 @@
+package retry
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ResettableBackoff is a multiplicative backoff whose state outlives a single
+// loop and can be returned to its base delay, for long-lived reconnect loops
+// that should not stay at the ceiling forever after an outage. It is reset
+// explicitly by Reset and, if it has a healthy interval, automatically by a
+// failure following a period of sustained success at least that long. Success
+// is observed by wrapping the Worker with ResettableBackoff.Worker. It is
+// safe for concurrent use.
+type ResettableBackoff struct {
+	mu      sync.Mutex
+	base    time.Duration
+	ceil    time.Duration
+	healthy time.Duration
+	next    func() time.Duration
+	// succeeding is the time since which every attempt has succeeded, or
+	// zero if the last attempt failed.
+	succeeding time.Time
+}
+
+// NewResettableBackoff returns a ResettableBackoff whose delay doubles from
+// base to ceil and which resets itself once attempts have succeeded for
+// healthy without a failure. A healthy interval of zero disables the
+// automatic reset.
+func NewResettableBackoff(base time.Duration, ceil time.Duration, healthy time.Duration) *ResettableBackoff {
+	return &ResettableBackoff{
+		base:    base,
+		ceil:    ceil,
+		healthy: healthy,
+		next:    exponential(base, 2, ceil),
+	}
+}
+
+// Reset returns the backoff to its base delay.
+func (b *ResettableBackoff) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.reset()
+}
+
+func (b *ResettableBackoff) reset() {
+	b.next = exponential(b.base, 2, b.ceil)
+}
+
+// Timer returns a Timer that sleeps for the current delay of the backoff and
+// then doubles it. The sleep may be canceled using a context.
+func (b *ResettableBackoff) Timer(ctx context.Context) Timer {
+	state := timerStateFrom(ctx)
+	return func() {
+		b.mu.Lock()
+		d := b.next()
+		b.mu.Unlock()
+		sleepContext(ctx, state.delay(d))
+	}
+}
+
+// Worker returns a Worker that records the outcome of each attempt of worker,
+// resetting the backoff when an attempt fails after attempts have succeeded
+// for the healthy interval.
+func (b *ResettableBackoff) Worker(worker Worker) Worker {
+	return func() error {
+		err := worker()
+		b.record(err == nil)
+		return err
+	}
+}
+
+func (b *ResettableBackoff) record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if success {
+		if b.succeeding.IsZero() {
+			b.succeeding = now()
+		}
+		return
+	}
+	if b.healthy > 0 && !b.succeeding.IsZero() && since(b.succeeding) >= b.healthy {
+		b.reset()
+	}
+	b.succeeding = time.Time{}
+}
@@ -0,0 +1,46 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/colvin/retry"
+	"github.com/colvin/retry/retrytest"
+)
+
+func TestResettableBackoffResetsAfterSustainedSuccess(t *testing.T) {
+	clock := retrytest.NewClock(time.Now())
+	defer clock.Install()()
+
+	b := retry.NewResettableBackoff(time.Second, time.Minute, time.Hour)
+	timer := b.Timer(context.Background())
+	errFail := errors.New("fail")
+	fail := b.Worker(func() error { return errFail })
+	succeed := b.Worker(func() error { return nil })
+
+	// Slow failures never reset the backoff.
+	for i := 0; i < 3; i++ {
+		fail()
+		clock.Advance(2 * time.Hour)
+		timer()
+	}
+	// A brief success does not either.
+	succeed()
+	clock.Advance(time.Minute)
+	fail()
+	timer()
+	// Sustained success does.
+	succeed()
+	clock.Advance(time.Hour)
+	succeed()
+	fail()
+	timer()
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, time.Second}
+	if got := clock.Sleeps(); !reflect.DeepEqual(got, want) {
+		t.Errorf("sleeps = %v, want %v", got, want)
+	}
+}
@@ -9,42 +9,77 @@ import (
 // Worker is a function that does some work.
 type Worker func() error
 
+// ContextWorker is a Worker that is passed the context of the loop.
+type ContextWorker func(context.Context) error
+
 // Limiter is a function that is called after the Worker has failed. It should
 // return true if further attempts should be made, false if no further attempts
 // should be made. It is passed the Worker's error to aid in its deliberation.
+// It is not consulted for errors marked with Permanent.
 type Limiter func(error) bool
 
 // Timer is a function that is called after the Limiter has indicated that
 // further attempts will be made. The next attempt will not be made until the
-// Timer has completed.
+// Timer has completed. If the Worker's error specifies the delay itself, the
+// loop sleeps for that delay instead; see RetryAfterError.
 type Timer func()
 
 // Retry implements a retry loop for the given Worker function. Attempts are
 // made in succession until the Worker returns without error or the Limiter
 // terminates the loop. The Timer is called between each attempt.
 func Retry(worker Worker, limiter Limiter, timer Timer) error {
-	err := worker()
-	for err != nil && limiter(err) {
-		timer()
-		err = worker()
+	for {
+		err := worker()
+		if err == nil {
+			return nil
+		}
+		if perm, ok := unwrapPermanent(err); ok {
+			return perm
+		}
+		if !limiter(err) {
+			return err
+		}
+		if after, ok := RetryAfter(err); ok {
+			sleep(after)
+		} else {
+			timer()
+		}
 	}
-	return err
+}
+
+// RetryContext is the same as Retry but passes ctx to each attempt of the
+// Worker. No further attempts are made once ctx is done or its deadline has
+// passed, either when the Limiter is evaluated or after the Timer has
+// returned; the error from the final attempt is returned. The Timer is not
+// canceled by ctx, so a cancelable Timer using the same context should be
+// used to avoid sleeping needlessly.
+func RetryContext(ctx context.Context, worker ContextWorker, limiter Limiter, timer Timer) error {
+	return run(ctx, worker, AttemptLimiter(limiter), AttemptTimer(timer))
 }
 
 // CancelableLimiter returns a Limiter that wraps another Limiter, adding the
 // ability to be canceled by a context before the interior Limiter is
-// evaluated.
+// evaluated. The Limiter also terminates the loop once the context's deadline
+// has passed.
 func CancelableLimiter(ctx context.Context, limiter Limiter) Limiter {
 	return func(err error) bool {
-		select {
-		case <-ctx.Done():
+		if expired(ctx) {
 			return false
-		default:
 		}
 		return limiter(err)
 	}
 }
 
+// expired reports whether ctx is done or its deadline has passed. The latter
+// may be true slightly before the former.
+func expired(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+	deadline, ok := ctx.Deadline()
+	return ok && !now().Before(deadline)
+}
+
 // Once returns a Limiter that immediately terminates the loop. The Worker is
 // always executed once before a Limiter is evaluated.
 func Once() Limiter {
@@ -61,7 +96,9 @@ func Forever() Limiter {
 }
 
 // Counts returns a Limiter that terminates the loop after the given number
-// of attempts have been made. Zero is treated the same as one.
+// of attempts have been made. Zero is treated the same as one. The Limiter
+// counts the attempts of a single loop and must not be shared; see
+// CountsPolicy.
 func Counts(max int) Limiter {
 	// First attempt counts
 	c := 1
@@ -74,6 +111,40 @@ func Counts(max int) Limiter {
 	}
 }
 
+// MaxElapsed returns a Limiter that terminates the loop once the given
+// duration has passed since it was first evaluated. Like Counts it tracks a
+// single loop and must not be shared; see MaxElapsedPolicy.
+func MaxElapsed(max time.Duration) Limiter {
+	var start time.Time
+	return func(_ error) bool {
+		if start.IsZero() {
+			start = now()
+		}
+		return since(start) < max
+	}
+}
+
+// SleepBudget wraps a Limiter and Timer pair so that the loop terminates once
+// the Timer has spent a total of max sleeping, regardless of how many attempts
+// have been made. The returned Limiter consults the budget before the
+// interior Limiter is evaluated. Both returned values track a single loop and
+// must not be shared.
+func SleepBudget(limiter Limiter, timer Timer, max time.Duration) (Limiter, Timer) {
+	var slept time.Duration
+	budgeted := func(err error) bool {
+		if slept >= max {
+			return false
+		}
+		return limiter(err)
+	}
+	timed := func() {
+		start := now()
+		timer()
+		slept += since(start)
+	}
+	return budgeted, timed
+}
+
 // UntilCanceled returns a Limiter that never terminates until it is canceled
 // by a context.
 func UntilCanceled(ctx context.Context) Limiter {
@@ -81,33 +152,21 @@ func UntilCanceled(ctx context.Context) Limiter {
 }
 
 // CancelableSleep returns a Timer that sleeps for the given duration but may
-// be canceled using a context.
+// be canceled using a context. The sleep is cut short at the context's
+// deadline, if it has one, rather than overshooting it; the context is then
+// done, so RetryContext will not start another attempt.
 func CancelableSleep(ctx context.Context, dur time.Duration) Timer {
+	state := timerStateFrom(ctx)
 	return func() {
-		timer := time.NewTimer(dur)
-		select {
-		case <-timer.C:
-			return
-		case <-ctx.Done():
-			timer.Stop()
-			return
-		}
+		sleepContext(ctx, state.delay(dur))
 	}
 }
 
 // MultiplicativeBackoff returns a Timer that sleeps for a duration, where the
-// duration doubles each iteration until a ceiling is reached.
+// duration doubles each iteration until a ceiling is reached. The Timer tracks
+// the backoff of a single loop and must not be shared; see BackoffPolicy.
 func MultiplicativeBackoff(base time.Duration, ceil time.Duration) Timer {
-	dur := base
-	return func() {
-		time.Sleep(dur)
-		if dur != ceil {
-			dur = dur * 2
-			if dur > ceil {
-				dur = ceil
-			}
-		}
-	}
+	return ExponentialBackoff(base, 2, ceil)
 }
 
 // CMB is an alias for the admittedly long-named
@@ -115,21 +174,28 @@ func MultiplicativeBackoff(base time.Duration, ceil time.Duration) Timer {
 var CMB = CancelableMultiplicativeBackoff
 
 // CancelableMultiplicativeBackoff is the same as MultiplicativeBackoff but can
-// be canceled using a context.
+// be canceled using a context. Like CancelableSleep it never sleeps past the
+// context's deadline.
 func CancelableMultiplicativeBackoff(ctx context.Context, base time.Duration, ceil time.Duration) Timer {
-	dur := base
-	return func() {
-		timer := time.NewTimer(dur)
-		select {
-		case <-timer.C:
-		case <-ctx.Done():
-			timer.Stop()
+	return CancelableExponentialBackoff(ctx, base, 2, ceil)
+}
+
+// sleepContext sleeps for the given duration or until ctx is done, whichever
+// happens first. It returns immediately if the deadline of ctx has passed.
+func sleepContext(ctx context.Context, dur time.Duration) {
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := until(deadline)
+		if remaining <= 0 {
+			return
 		}
-		if dur != ceil {
-			dur = dur * 2
-			if dur > ceil {
-				dur = ceil
-			}
+		if remaining < dur {
+			dur = remaining
 		}
 	}
+	timer := clock.Load().NewTimer(dur)
+	select {
+	case <-timer.C():
+	case <-ctx.Done():
+		timer.Stop()
+	}
 }
@@ -0,0 +1,158 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Retryer is a reusable retry configuration. It is built once using New and
+// may then be stored and used for any number of loops. Every call to Do
+// constructs a fresh Limiter and Timer, so a Retryer is safe for concurrent
+// use. A Retryer is configured with LimiterPolicies and TimerPolicies rather
+// than Limiters and Timers for the same reason.
+//
+// By default a Retryer makes attempts until the Worker succeeds or its
+// context is canceled, without sleeping between attempts.
+type Retryer struct {
+	ctx             context.Context
+	limiters        []LimiterPolicy
+	timer           TimerPolicy
+	hooks           hooks
+	fallback        func(error) error
+	cancelError     bool
+	controller      *Controller
+	deadlineLimiter bool
+	exhaustedError  bool
+}
+
+// Option configures a Retryer.
+type Option func(*Retryer)
+
+// New returns a Retryer configured by the given options.
+func New(opts ...Option) *Retryer {
+	r := &Retryer{
+		ctx: context.Background(),
+		timer: func(_ context.Context) Timer {
+			return func() {}
+		},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// WithContext sets the context of the loops made by the Retryer. No further
+// attempts are made once it is done and the Timer is canceled by it.
+func WithContext(ctx context.Context) Option {
+	return func(r *Retryer) {
+		r.ctx = ctx
+	}
+}
+
+// WithMaxAttempts limits each loop to the given number of attempts, as with
+// Counts.
+func WithMaxAttempts(max int) Option {
+	return WithLimiter(CountsPolicy(max))
+}
+
+// WithMaxElapsed limits each loop to the given duration, as with MaxElapsed.
+func WithMaxElapsed(max time.Duration) Option {
+	return WithLimiter(MaxElapsedPolicy(max))
+}
+
+// WithBackoff sleeps between attempts as with CancelableMultiplicativeBackoff.
+func WithBackoff(base time.Duration, ceil time.Duration) Option {
+	return WithTimer(BackoffPolicy(base, ceil))
+}
+
+// WithLimiter adds a Limiter to the Retryer. If more than one Limiter is
+// added every one of them must allow a further attempt for one to be made.
+func WithLimiter(limiter LimiterPolicy) Option {
+	return func(r *Retryer) {
+		r.limiters = append(r.limiters, limiter)
+	}
+}
+
+// WithTimer sets the Timer of the Retryer, replacing any backoff.
+func WithTimer(timer TimerPolicy) Option {
+	return func(r *Retryer) {
+		r.timer = timer
+	}
+}
+
+// WithFallback sets a function to be called when a loop terminates without
+// success, whether because of the Limiter, a permanent error or the
+// cancellation of the loop's context. It is called exactly once with the
+// final error and its result is returned in place of that error, so
+// returning nil indicates that it recovered from the failure.
+func WithFallback(fallback func(error) error) Option {
+	return func(r *Retryer) {
+		r.fallback = fallback
+	}
+}
+
+// Do runs a retry loop for the given Worker.
+func (r *Retryer) Do(worker Worker) error {
+	return r.do(r.ctx, func(_ context.Context) error {
+		return worker()
+	}, true)
+}
+
+// DoContext runs a retry loop for the given ContextWorker using ctx in place
+// of the Retryer's context.
+func (r *Retryer) DoContext(ctx context.Context, worker ContextWorker) error {
+	return r.do(ctx, worker, false)
+}
+
+// do runs a retry loop for the given ContextWorker, which ignores its context
+// if contextFree is set.
+func (r *Retryer) do(ctx context.Context, worker ContextWorker, contextFree bool) error {
+	run := func(ctx context.Context, worker ContextWorker) (StopReason, error) {
+		return r.run(ctx, worker, contextFree)
+	}
+	var err error
+	if r.controller != nil {
+		err = r.controller.run(ctx, worker, run)
+	} else {
+		_, err = run(ctx, worker)
+	}
+	if err != nil && r.fallback != nil {
+		return r.fallback(err)
+	}
+	return err
+}
+
+// run runs a single loop for the given ContextWorker, returning the reason
+// it terminated along with its error.
+func (r *Retryer) run(ctx context.Context, worker ContextWorker, contextFree bool) (StopReason, error) {
+	limiter := AttemptLimiter(r.limiter())
+	if r.deadlineLimiter {
+		deadline := DeadlineLimiter(ctx)
+		base := limiter
+		limiter = func(a Attempt) bool {
+			return deadline(a) && base(a)
+		}
+	}
+	tctx, state := withTimerState(ctx)
+	l := loop{
+		limiter:        limiter,
+		timer:          AttemptTimer(r.timer(tctx)),
+		hooks:          r.hooks,
+		cancelError:    r.cancelError,
+		exhaustedError: r.exhaustedError,
+		state:          state,
+		contextFree:    contextFree,
+	}
+	err := l.run(ctx, worker)
+	return l.reason, err
+}
+
+// limiter constructs the Limiter for a single loop.
+func (r *Retryer) limiter() Limiter {
+	limiters := make([]Limiter, len(r.limiters))
+	for i, l := range r.limiters {
+		limiters[i] = l()
+	}
+	return All(limiters...)
+}
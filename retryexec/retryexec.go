@@ -0,0 +1,140 @@
+// Package retryexec retries external commands, such as flaky command line
+// tools, that fail with recognizably transient errors.
+package retryexec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"regexp"
+	"time"
+
+	"github.com/colvin/retry"
+)
+
+// DefaultRetryer is the Retryer used by a Runner without one.
+var DefaultRetryer = retry.New(
+	retry.WithMaxAttempts(3),
+	retry.WithBackoff(time.Second, 30*time.Second),
+)
+
+// Output is the output captured from one attempt to run a command.
+type Output struct {
+	// Stdout and Stderr are everything the command wrote to its standard
+	// output and standard error.
+	Stdout []byte
+	Stderr []byte
+	// ExitCode is the command's exit status, or -1 if it did not exit
+	// normally, was killed or could not be started.
+	ExitCode int
+	// Err is the error with which the attempt failed, or nil if it
+	// succeeded.
+	Err error
+}
+
+// Runner runs commands in retry loops. Each attempt runs a new command
+// constructed by a factory. Commands that cannot be started are not retried.
+// A command that exits with a non-zero status is retried if its exit code is
+// listed in ExitCodes or its standard error matches any of Stderr; if neither
+// is set, every non-zero exit is retried.
+//
+// When the context is done, the running command is killed and no further
+// attempts are made.
+type Runner struct {
+	// Retryer makes the loops. If nil, DefaultRetryer is used.
+	Retryer *retry.Retryer
+	// ExitCodes are the retryable exit codes.
+	ExitCodes []int
+	// Stderr are patterns matching the standard error of retryable
+	// failures.
+	Stderr []*regexp.Regexp
+}
+
+// Run runs the commands returned by cmd until one succeeds or the loop
+// terminates, returning the output of every attempt and the loop's error.
+// cmd is called once for each attempt and must return a new, unstarted
+// command. If the command's Stdout or Stderr is set, its output is also
+// written there.
+func (r Runner) Run(ctx context.Context, cmd func(context.Context) *exec.Cmd) ([]Output, error) {
+	retryer := r.Retryer
+	if retryer == nil {
+		retryer = DefaultRetryer
+	}
+	var outputs []Output
+	err := retryer.DoContext(ctx, func(ctx context.Context) error {
+		out, started := r.attempt(ctx, cmd(ctx))
+		outputs = append(outputs, out)
+		if out.Err != nil && (!started || !r.retryable(out)) {
+			return retry.Permanent(out.Err)
+		}
+		return out.Err
+	})
+	return outputs, err
+}
+
+// attempt runs c, killing it if ctx is done first. It reports whether c
+// could be started.
+func (r Runner) attempt(ctx context.Context, c *exec.Cmd) (Output, bool) {
+	var stdout, stderr bytes.Buffer
+	c.Stdout = tee(c.Stdout, &stdout)
+	c.Stderr = tee(c.Stderr, &stderr)
+	if err := c.Start(); err != nil {
+		return Output{ExitCode: -1, Err: err}, false
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Wait()
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		c.Process.Kill()
+		<-done
+		err = ctx.Err()
+	}
+	return Output{
+		Stdout:   stdout.Bytes(),
+		Stderr:   stderr.Bytes(),
+		ExitCode: c.ProcessState.ExitCode(),
+		Err:      err,
+	}, true
+}
+
+// retryable reports whether the failed attempt out should be retried.
+func (r Runner) retryable(out Output) bool {
+	var exit *exec.ExitError
+	if !errors.As(out.Err, &exit) {
+		return true
+	}
+	if r.ExitCodes == nil && r.Stderr == nil {
+		return true
+	}
+	for _, code := range r.ExitCodes {
+		if out.ExitCode == code {
+			return true
+		}
+	}
+	for _, re := range r.Stderr {
+		if re.Match(out.Stderr) {
+			return true
+		}
+	}
+	return false
+}
+
+// tee returns a writer writing to both w and buf, or only to buf if w is nil.
+func tee(w io.Writer, buf *bytes.Buffer) io.Writer {
+	if w == nil {
+		return buf
+	}
+	return io.MultiWriter(w, buf)
+}
+
+// Run runs the commands returned by cmd using a Runner with the given
+// Retryer, retrying every non-zero exit.
+func Run(ctx context.Context, r *retry.Retryer, cmd func(context.Context) *exec.Cmd) ([]Output, error) {
+	return Runner{Retryer: r}.Run(ctx, cmd)
+}
@@ -0,0 +1,135 @@
+// Package retrygrpc implements gRPC client interceptors that retry calls
+// using the retry loop of package retry.
+package retrygrpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/colvin/retry"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultCodes are the status codes retried by an interceptor without
+// WithCodes.
+var DefaultCodes = []codes.Code{codes.Unavailable, codes.ResourceExhausted}
+
+// DefaultLimiter is the LimiterPolicy used by an interceptor without
+// WithLimiter.
+var DefaultLimiter = retry.CountsPolicy(3)
+
+// DefaultTimer is the TimerPolicy used by an interceptor without WithTimer.
+var DefaultTimer = retry.BackoffPolicy(100*time.Millisecond, 5*time.Second)
+
+// Option configures an interceptor.
+type Option func(*config)
+
+type config struct {
+	codes   map[codes.Code]bool
+	limiter retry.LimiterPolicy
+	timer   retry.TimerPolicy
+}
+
+// WithCodes sets the status codes that are retried. Calls failing with any
+// other code are not retried.
+func WithCodes(retryable ...codes.Code) Option {
+	return func(c *config) {
+		c.codes = make(map[codes.Code]bool, len(retryable))
+		for _, code := range retryable {
+			c.codes[code] = true
+		}
+	}
+}
+
+// WithLimiter sets the LimiterPolicy deciding whether to retry a call.
+func WithLimiter(limiter retry.LimiterPolicy) Option {
+	return func(c *config) {
+		c.limiter = limiter
+	}
+}
+
+// WithTimer sets the TimerPolicy waiting between attempts of a call.
+func WithTimer(timer retry.TimerPolicy) Option {
+	return func(c *config) {
+		c.timer = timer
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		limiter: DefaultLimiter,
+		timer:   DefaultTimer,
+	}
+	WithCodes(DefaultCodes...)(c)
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// UnaryClientInterceptor returns an interceptor that retries unary calls
+// failing with a retryable status code. A delay given by a RetryInfo detail
+// in the status is slept for in place of the Timer's own delay, as described
+// by retry.RetryAfterError. No further attempts are made once the call's
+// context is done.
+func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
+	c := newConfig(opts)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		return c.retry(ctx, func(ctx context.Context) error {
+			return invoker(ctx, method, req, reply, cc, callOpts...)
+		})
+	}
+}
+
+// StreamClientInterceptor returns an interceptor that retries the
+// establishment of streams as UnaryClientInterceptor retries unary calls.
+// Errors occurring once a stream has been established are not retried.
+func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
+	c := newConfig(opts)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		var stream grpc.ClientStream
+		err := c.retry(ctx, func(ctx context.Context) error {
+			var err error
+			stream, err = streamer(ctx, desc, cc, method, callOpts...)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return stream, nil
+	}
+}
+
+// retry runs a retry loop for a call, returning the call's error unchanged.
+func (c *config) retry(ctx context.Context, call retry.ContextWorker) error {
+	err := retry.RetryPolicy(ctx, func(ctx context.Context) error {
+		return c.classify(call(ctx))
+	}, c.limiter, c.timer)
+	var ra *retry.RetryAfterError
+	if errors.As(err, &ra) {
+		return ra.Err
+	}
+	return err
+}
+
+// classify marks errors with non-retryable codes as permanent and attaches
+// any delay requested by the server to the others.
+func (c *config) classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok || !c.codes[st.Code()] {
+		return retry.Permanent(err)
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok && info.GetRetryDelay() != nil {
+			return &retry.RetryAfterError{Err: err, Delay: info.GetRetryDelay().AsDuration()}
+		}
+	}
+	return err
+}
@@ -0,0 +1,104 @@
+package retrygrpc_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/colvin/retry"
+	"github.com/colvin/retry/retrygrpc"
+	"github.com/colvin/retry/retrytest"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// invoker returns a grpc.UnaryInvoker failing with err, counting its calls.
+func invoker(err error, calls *int) grpc.UnaryInvoker {
+	return func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+		*calls++
+		return err
+	}
+}
+
+func TestUnaryClientInterceptorRetryableCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []retrygrpc.Option
+		code codes.Code
+		want int
+	}{
+		{name: "unavailable", code: codes.Unavailable, want: 3},
+		{name: "resource exhausted", code: codes.ResourceExhausted, want: 3},
+		{name: "internal", code: codes.Internal, want: 1},
+		{name: "invalid argument", code: codes.InvalidArgument, want: 1},
+		{name: "custom retried", opts: []retrygrpc.Option{retrygrpc.WithCodes(codes.Internal)}, code: codes.Internal, want: 3},
+		{name: "custom not retried", opts: []retrygrpc.Option{retrygrpc.WithCodes(codes.Internal)}, code: codes.Unavailable, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer retrytest.NewClock(time.Now()).Install()()
+
+			var calls int
+			interceptor := retrygrpc.UnaryClientInterceptor(tt.opts...)
+			err := interceptor(context.Background(), "/svc/Method", nil, nil, nil, invoker(status.Error(tt.code, "fail"), &calls))
+			if status.Code(err) != tt.code {
+				t.Errorf("code = %v, want %v", status.Code(err), tt.code)
+			}
+			if retry.IsPermanent(err) {
+				t.Errorf("err = %v is still marked permanent", err)
+			}
+			if calls != tt.want {
+				t.Errorf("calls = %d, want %d", calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnaryClientInterceptorHonorsRetryInfo(t *testing.T) {
+	clock := retrytest.NewClock(time.Now())
+	defer clock.Install()()
+
+	st, err := status.New(codes.Unavailable, "overloaded").WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(7 * time.Second),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls int
+	err = retrygrpc.UnaryClientInterceptor()(context.Background(), "/svc/Method", nil, nil, nil, invoker(st.Err(), &calls))
+
+	want := []time.Duration{7 * time.Second, 7 * time.Second}
+	if got := clock.Sleeps(); !reflect.DeepEqual(got, want) {
+		t.Errorf("sleeps = %v, want %v", got, want)
+	}
+	// The call's error is returned unchanged.
+	var ra *retry.RetryAfterError
+	if errors.As(err, &ra) {
+		t.Errorf("err = %#v, want the RetryAfterError unwrapped", err)
+	}
+	if got, ok := status.FromError(err); !ok || got.Code() != codes.Unavailable || len(got.Details()) != 1 {
+		t.Errorf("err = %v, want the call's status with its RetryInfo", err)
+	}
+}
+
+func TestStreamClientInterceptorRetriesEstablishment(t *testing.T) {
+	defer retrytest.NewClock(time.Now()).Install()()
+
+	var calls int
+	streamer := func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+		calls++
+		if calls < 3 {
+			return nil, status.Error(codes.Unavailable, "fail")
+		}
+		return nil, nil
+	}
+	_, err := retrygrpc.StreamClientInterceptor()(context.Background(), &grpc.StreamDesc{}, nil, "/svc/Stream", streamer)
+	if err != nil || calls != 3 {
+		t.Errorf("err = %v after %d calls, want nil after 3", err, calls)
+	}
+}
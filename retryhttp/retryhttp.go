@@ -0,0 +1,169 @@
+// Package retryhttp implements an http.RoundTripper that retries requests
+// using the retry loop of package retry.
+package retryhttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/colvin/retry"
+)
+
+// DefaultLimiter is the LimiterPolicy used by a Transport without a Limiter.
+var DefaultLimiter = retry.CountsPolicy(3)
+
+// DefaultTimer is the TimerPolicy used by a Transport without a Timer.
+var DefaultTimer = retry.BackoffPolicy(100*time.Millisecond, 5*time.Second)
+
+// DefaultMaxRetryAfter is the longest Retry-After delay waited for by a
+// Transport without a MaxRetryAfter.
+const DefaultMaxRetryAfter = 30 * time.Second
+
+// Transport is an http.RoundTripper that retries requests that fail with a
+// transport error or a retryable response, by default one with one of
+// DefaultStatuses. The Limiter sees a retryable response as a *StatusError. Only
+// requests that can safely be replayed are retried: those with an idempotent
+// method or an Idempotency-Key header, and a body that is either empty or can
+// be recreated using the request's GetBody. Other requests are made once.
+//
+// The Limiter and Timer are constructed for each request, the Timer being
+// passed the request's context. No further attempts are made once the
+// request's context is done.
+//
+// A Retry-After header on a retryable response is slept for in place of the
+// Timer's own delay, as described by retry.RetryAfterError. If it asks for a
+// longer delay than MaxRetryAfter, the response is returned rather than
+// waited out.
+//
+// If the Limiter terminates the loop after a retryable response, that
+// response is returned to the caller as it would have been without a
+// Transport.
+type Transport struct {
+	// Base makes each attempt. If nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+	// Limiter decides whether to retry. If nil, DefaultLimiter is used.
+	Limiter retry.LimiterPolicy
+	// Timer waits between attempts. If nil, DefaultTimer is used.
+	Timer retry.TimerPolicy
+	// Statuses are the retryable response status codes. If nil,
+	// DefaultStatuses are retryable.
+	Statuses []int
+	// MaxRetryAfter is the longest Retry-After delay to wait for. If zero,
+	// DefaultMaxRetryAfter is used.
+	MaxRetryAfter time.Duration
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if !replayable(req) {
+		return base.RoundTrip(req)
+	}
+	limiter := t.Limiter
+	if limiter == nil {
+		limiter = DefaultLimiter
+	}
+	timer := t.Timer
+	if timer == nil {
+		timer = DefaultTimer
+	}
+
+	var attempts int
+	var resp *http.Response
+	err := retry.RetryPolicy(req.Context(), func(ctx context.Context) error {
+		attempts++
+		if resp != nil {
+			discard(resp)
+			resp = nil
+		}
+		r := req
+		if attempts > 1 {
+			r = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return retry.Permanent(err)
+				}
+				r.Body = body
+			}
+		}
+		res, err := base.RoundTrip(r)
+		if err != nil {
+			return err
+		}
+		resp = res
+		if !t.retryable(res.StatusCode) {
+			return nil
+		}
+		err = statusError(res)
+		if after, ok := retry.RetryAfter(err); ok && after > t.maxRetryAfter() {
+			return retry.Permanent(err)
+		}
+		return err
+	}, limiter, timer)
+	if resp != nil {
+		return resp, nil
+	}
+	return nil, err
+}
+
+// retryable reports whether a response with the given status code should be
+// retried.
+func (t *Transport) retryable(code int) bool {
+	if t.Statuses == nil {
+		return contains(DefaultStatuses, code)
+	}
+	return contains(t.Statuses, code)
+}
+
+// maxRetryAfter returns the longest Retry-After delay to wait for.
+func (t *Transport) maxRetryAfter() time.Duration {
+	if t.MaxRetryAfter == 0 {
+		return DefaultMaxRetryAfter
+	}
+	return t.MaxRetryAfter
+}
+
+// retryAfter parses the value of a Retry-After header, which is either a
+// number of seconds or an HTTP date.
+func retryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t), true
+	}
+	return 0, false
+}
+
+// replayable reports whether req can safely be made more than once.
+func replayable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// discard drains and closes the body of a response that will not be returned
+// so that its connection can be reused.
+func discard(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+}
@@ -0,0 +1,228 @@
+package retryhttp_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/colvin/retry/retryhttp"
+	"github.com/colvin/retry/retrytest"
+)
+
+// server is an httptest server responding to the nth request with the status
+// returned by status, recording the bodies of the requests.
+type server struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	bodies []string
+}
+
+func newServer(t *testing.T, status func(n int) (int, http.Header)) *server {
+	t.Helper()
+	s := &server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.bodies = append(s.bodies, string(body))
+		n := len(s.bodies)
+		s.mu.Unlock()
+		code, header := status(n)
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(code)
+		io.WriteString(w, "attempt "+strconv.Itoa(n))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *server) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.bodies...)
+}
+
+func client() *http.Client {
+	return &http.Client{Transport: &retryhttp.Transport{}}
+}
+
+func installClock(t *testing.T) {
+	t.Cleanup(retrytest.NewClock(time.Now()).Install())
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestTransportReplaysBody(t *testing.T) {
+	installClock(t)
+	s := newServer(t, func(n int) (int, http.Header) {
+		if n < 3 {
+			return http.StatusServiceUnavailable, nil
+		}
+		return http.StatusOK, nil
+	})
+	req, err := http.NewRequest(http.MethodPut, s.URL, strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || readBody(t, resp) != "attempt 3" {
+		t.Errorf("response %d, want 200 from attempt 3", resp.StatusCode)
+	}
+	for i, body := range s.requests() {
+		if body != "payload" {
+			t.Errorf("body of request %d = %q, want %q", i+1, body, "payload")
+		}
+	}
+}
+
+func TestTransportDoesNotRetryNonIdempotentRequests(t *testing.T) {
+	installClock(t)
+	s := newServer(t, func(int) (int, http.Header) {
+		return http.StatusServiceUnavailable, nil
+	})
+	resp, err := client().Post(s.URL, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if n := len(s.requests()); n != 1 || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("%d requests ending with %d, want 1 ending with 503", n, resp.StatusCode)
+	}
+
+	// The same request is retried with an Idempotency-Key.
+	req, err := http.NewRequest(http.MethodPost, s.URL, strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Idempotency-Key", "key")
+	resp, err = client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if n := len(s.requests()); n != 4 {
+		t.Errorf("%d requests, want 4", n)
+	}
+}
+
+func TestTransportRetriesDefaultStatusesOnly(t *testing.T) {
+	installClock(t)
+	s := newServer(t, func(int) (int, http.Header) {
+		return http.StatusNotImplemented, nil
+	})
+	resp, err := client().Get(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if n := len(s.requests()); n != 1 {
+		t.Errorf("%d requests for a 501 response, want 1", n)
+	}
+}
+
+func TestTransportReturnsLastResponseWhenLimiterGivesUp(t *testing.T) {
+	installClock(t)
+	s := newServer(t, func(int) (int, http.Header) {
+		return http.StatusBadGateway, nil
+	})
+	resp, err := client().Get(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("status = %d, want 502", resp.StatusCode)
+	}
+	if body := readBody(t, resp); body != "attempt 3" {
+		t.Errorf("body = %q, want that of the last attempt", body)
+	}
+}
+
+func TestTransportReturnsLongRetryAfter(t *testing.T) {
+	clock := retrytest.NewClock(time.Now())
+	t.Cleanup(clock.Install())
+	s := newServer(t, func(int) (int, http.Header) {
+		return http.StatusTooManyRequests, http.Header{"Retry-After": {"60"}}
+	})
+	c := &http.Client{Transport: &retryhttp.Transport{MaxRetryAfter: time.Second}}
+	resp, err := c.Get(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if n := len(s.requests()); n != 1 || resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("%d requests ending with %d, want 1 ending with 429", n, resp.StatusCode)
+	}
+	if sleeps := clock.Sleeps(); len(sleeps) != 0 {
+		t.Errorf("sleeps = %v, want none", sleeps)
+	}
+}
+
+// trackedBody is a response body recording whether it was drained and
+// closed.
+type trackedBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func (b *trackedBody) drained() bool {
+	n, _ := b.Read(make([]byte, 1))
+	return n == 0
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTransportDrainsDiscardedResponses(t *testing.T) {
+	installClock(t)
+	var bodies []*trackedBody
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		body := &trackedBody{Reader: strings.NewReader("unavailable")}
+		bodies = append(bodies, body)
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: body, Request: req}, nil
+	})
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&retryhttp.Transport{Base: base}).RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 3 || resp.Body != bodies[2] {
+		t.Fatalf("%d responses, want the last of 3 returned", len(bodies))
+	}
+	for i, body := range bodies[:2] {
+		if !body.closed || !body.drained() {
+			t.Errorf("discarded response %d: closed %v, drained %v", i+1, body.closed, body.drained())
+		}
+	}
+	if bodies[2].closed {
+		t.Error("returned response was closed")
+	}
+}
@@ -0,0 +1,92 @@
+package retryhttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/colvin/retry"
+)
+
+// DefaultStatuses are the status codes treated as retryable by CheckResponse
+// and StatusLimiter when none are given, and by a Transport without
+// Statuses: 429, 502, 503 and 504.
+var DefaultStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// StatusError is an error describing an unsuccessful HTTP response.
+type StatusError struct {
+	// Code is the status code of the response.
+	Code int
+	// Status is the status line of the response, such as "503 Service
+	// Unavailable".
+	Status string
+}
+
+func (e *StatusError) Error() string {
+	return "retryhttp: unsuccessful response: " + e.Status
+}
+
+// CheckResponse converts an HTTP response into an error for use by a Worker.
+// It returns nil for a response with a status below 400. A response with one
+// of the given retryable statuses, or DefaultStatuses if none are given,
+// returns a *StatusError carrying the delay of any Retry-After header; any
+// other response returns a *StatusError marked with retry.Permanent. The
+// response body is not read or closed.
+func CheckResponse(resp *http.Response, statuses ...int) error {
+	if resp.StatusCode < 400 {
+		return nil
+	}
+	if len(statuses) == 0 {
+		statuses = DefaultStatuses
+	}
+	if !contains(statuses, resp.StatusCode) {
+		return retry.Permanent(&StatusError{Code: resp.StatusCode, Status: status(resp)})
+	}
+	return statusError(resp)
+}
+
+// StatusLimiter returns a Limiter that allows a further attempt only if the
+// error is a *StatusError with one of the given statuses, or DefaultStatuses
+// if none are given.
+func StatusLimiter(statuses ...int) retry.Limiter {
+	if len(statuses) == 0 {
+		statuses = DefaultStatuses
+	}
+	return func(err error) bool {
+		var se *StatusError
+		return errors.As(err, &se) && contains(statuses, se.Code)
+	}
+}
+
+// statusError returns the error of a retryable response, carrying the delay
+// of any Retry-After header.
+func statusError(resp *http.Response) error {
+	err := &StatusError{Code: resp.StatusCode, Status: status(resp)}
+	if after, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+		return &retry.RetryAfterError{Err: err, Delay: after}
+	}
+	return err
+}
+
+// status returns the status line of a response, synthesizing it if the
+// response does not have one.
+func status(resp *http.Response) string {
+	if resp.Status != "" {
+		return resp.Status
+	}
+	return fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+}
+
+func contains(statuses []int, code int) bool {
+	for _, s := range statuses {
+		if s == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+// Package retrymetrics exports Prometheus metrics describing the loops made
+// by retry.Retryers.
+package retrymetrics
+
+import (
+	"time"
+
+	"github.com/colvin/retry"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector is a prometheus.Collector tracking the attempts, successes,
+// exhaustions and cumulative backoff time of retry loops, labeled by the name
+// of the operation being retried. Loops are recorded by configuring a
+// Retryer with the Option of the Collector. It is safe for concurrent use.
+type Collector struct {
+	attempts    *prometheus.CounterVec
+	successes   *prometheus.CounterVec
+	exhaustions *prometheus.CounterVec
+	backoff     *prometheus.CounterVec
+}
+
+// NewCollector returns a Collector whose metrics are in the given namespace.
+// It must be registered with a prometheus.Registerer to be exported.
+func NewCollector(namespace string) *Collector {
+	labels := []string{"operation"}
+	return &Collector{
+		attempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "retry",
+			Name:      "attempts_total",
+			Help:      "Attempts made by retry loops.",
+		}, labels),
+		successes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "retry",
+			Name:      "successes_total",
+			Help:      "Retry loops that ended with a successful attempt.",
+		}, labels),
+		exhaustions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "retry",
+			Name:      "exhaustions_total",
+			Help:      "Retry loops that gave up without a successful attempt.",
+		}, labels),
+		backoff: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "retry",
+			Name:      "backoff_seconds_total",
+			Help:      "Time spent waiting between attempts of retry loops.",
+		}, labels),
+	}
+}
+
+// Option returns a retry.Option that records the loops of a Retryer as the
+// named operation.
+func (c *Collector) Option(operation string) retry.Option {
+	attempts := c.attempts.WithLabelValues(operation)
+	successes := c.successes.WithLabelValues(operation)
+	exhaustions := c.exhaustions.WithLabelValues(operation)
+	backoff := c.backoff.WithLabelValues(operation)
+	return func(r *retry.Retryer) {
+		retry.OnRetry(func(_ retry.Attempt, delay time.Duration) {
+			backoff.Add(delay.Seconds())
+		})(r)
+		retry.OnSuccess(func(a retry.Attempt) {
+			attempts.Add(float64(a.Number))
+			successes.Inc()
+		})(r)
+		retry.OnGiveUp(func(a retry.Attempt) {
+			attempts.Add(float64(a.Number))
+			exhaustions.Inc()
+		})(r)
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.attempts.Describe(ch)
+	c.successes.Describe(ch)
+	c.exhaustions.Describe(ch)
+	c.backoff.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.attempts.Collect(ch)
+	c.successes.Collect(ch)
+	c.exhaustions.Collect(ch)
+	c.backoff.Collect(ch)
+}
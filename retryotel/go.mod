@@ -0,0 +1,14 @@
+module github.com/colvin/retry/retryotel
+
+go 1.25.0
+
+require (
+	github.com/colvin/retry v0.0.0
+	go.opentelemetry.io/otel v1.46.0
+	go.opentelemetry.io/otel/trace v1.46.0
+)
+
+require github.com/cespare/xxhash/v2 v2.3.0 // indirect
+
+// The tracing tracks the retry package in this repository.
+replace github.com/colvin/retry => ../
@@ -0,0 +1,57 @@
+// Package retryotel traces retry loops using OpenTelemetry.
+package retryotel
+
+import (
+	"context"
+	"time"
+
+	"github.com/colvin/retry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys recorded on spans and their events.
+const (
+	AttemptKey  = attribute.Key("retry.attempt")
+	AttemptsKey = attribute.Key("retry.attempts")
+	DelayKey    = attribute.Key("retry.delay_ms")
+)
+
+// Retry is the same as retry.RetryContext but wraps the loop in a span named
+// name, started using tracer. The context passed to the Worker carries the
+// span. Each failed attempt is recorded on the span as an exception event
+// carrying the attempt number, and each retry as a "retry" event carrying the
+// attempt number and the time waited before it. When the loop ends the total
+// number of attempts is set as an attribute and the span's status is set to
+// Ok or, with the final error, Error.
+func Retry(ctx context.Context, tracer trace.Tracer, name string, worker retry.ContextWorker, limiter retry.Limiter, timer retry.Timer) error {
+	ctx, span := tracer.Start(ctx, name)
+	defer span.End()
+
+	var attempts int
+	var failed time.Time
+	err := retry.RetryContext(ctx, func(ctx context.Context) error {
+		attempts++
+		if attempts > 1 {
+			span.AddEvent("retry", trace.WithAttributes(
+				AttemptKey.Int(attempts),
+				DelayKey.Int64(time.Since(failed).Milliseconds()),
+			))
+		}
+		err := worker(ctx)
+		if err != nil {
+			failed = time.Now()
+			span.RecordError(err, trace.WithAttributes(AttemptKey.Int(attempts)))
+		}
+		return err
+	}, limiter, timer)
+
+	span.SetAttributes(AttemptsKey.Int(attempts))
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+	return err
+}
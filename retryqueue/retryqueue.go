@@ -0,0 +1,227 @@
+// Package retryqueue retries tasks whose retries must outlive the process,
+// such as delivering webhooks or sending email. Tasks are persisted in a
+// Store along with their retry policy and progress, so that a Queue started
+// after a restart resumes them with the attempts and backoff they have left.
+package retryqueue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/colvin/retry"
+)
+
+// Task is a unit of work in a Queue.
+type Task struct {
+	// ID identifies the task within its Store.
+	ID string `json:"id"`
+	// Kind and Payload describe the work to the Handler.
+	Kind    string `json:"kind"`
+	Payload []byte `json:"payload,omitempty"`
+	// Policy limits the attempts and computes the delays between them.
+	Policy retry.Config `json:"policy"`
+	// Attempts is the number of attempts made so far.
+	Attempts int `json:"attempts"`
+	// Enqueued is the time at which the task was enqueued, from which the
+	// Policy's MaxElapsed is measured.
+	Enqueued time.Time `json:"enqueued"`
+	// NextRun is the time at which the next attempt is due.
+	NextRun time.Time `json:"next_run"`
+	// LastError is the message of the error of the last attempt.
+	LastError string `json:"last_error,omitempty"`
+}
+
+// Store persists the tasks of a Queue. Its methods may be called
+// concurrently.
+type Store interface {
+	// Put saves a task, replacing any with the same ID.
+	Put(Task) error
+	// Delete removes the task with the given ID, if any.
+	Delete(id string) error
+	// List returns every saved task.
+	List() ([]Task, error)
+}
+
+// ErrInterrupted is the error with which a task is passed to a Queue's Dead
+// function if its last permitted attempt was made but never completed, for
+// example because the process crashed during it.
+var ErrInterrupted = errors.New("retryqueue: last attempt interrupted")
+
+// Handler makes one attempt at a task. As in a retry loop, a Permanent error
+// ends the task's retries and a RetryAfterError takes the place of the
+// backoff before the next attempt.
+type Handler func(ctx context.Context, t Task) error
+
+// Queue runs the tasks in a Store, retrying each according to its Policy.
+type Queue struct {
+	// Dead, if not nil, is called with each task removed from the Queue
+	// without having succeeded and the error of its last attempt, or
+	// ErrInterrupted.
+	Dead func(Task, error)
+
+	store   Store
+	handler Handler
+	wake    chan struct{}
+}
+
+// New returns a Queue running the tasks in store with handler.
+func New(store Store, handler Handler) *Queue {
+	return &Queue{
+		store:   store,
+		handler: handler,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Enqueue saves a new task to be attempted as soon as possible and returns
+// its ID. It returns an error if policy is invalid or the task cannot be
+// saved.
+func (q *Queue) Enqueue(kind string, payload []byte, policy retry.Config) (string, error) {
+	if err := policy.Validate(); err != nil {
+		return "", err
+	}
+	id, err := newID()
+	if err != nil {
+		return "", err
+	}
+	now := retry.CurrentClock().Now()
+	t := Task{
+		ID:       id,
+		Kind:     kind,
+		Payload:  payload,
+		Policy:   policy,
+		Enqueued: now,
+		NextRun:  now,
+	}
+	if err := q.store.Put(t); err != nil {
+		return "", err
+	}
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return id, nil
+}
+
+// Run attempts tasks as they become due until ctx is done, returning
+// ctx.Err(), or the Store fails, returning its error. Tasks are attempted one
+// at a time. Only one Run should be active for a Store at a time.
+func (q *Queue) Run(ctx context.Context) error {
+	for {
+		tasks, err := q.store.List()
+		if err != nil {
+			return err
+		}
+		var next time.Time
+		for _, t := range tasks {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if retry.CurrentClock().Now().Before(t.NextRun) {
+				if next.IsZero() || t.NextRun.Before(next) {
+					next = t.NextRun
+				}
+				continue
+			}
+			t, err := q.attempt(ctx, t)
+			if err != nil {
+				return err
+			}
+			if t != nil && (next.IsZero() || t.NextRun.Before(next)) {
+				next = t.NextRun
+			}
+		}
+		if err := q.wait(ctx, next); err != nil {
+			return err
+		}
+	}
+}
+
+// wait waits until next, if not zero, or until a task is enqueued.
+func (q *Queue) wait(ctx context.Context, next time.Time) error {
+	var timeout <-chan time.Time
+	if !next.IsZero() {
+		clock := retry.CurrentClock()
+		timer := clock.NewTimer(next.Sub(clock.Now()))
+		defer timer.Stop()
+		timeout = timer.C()
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-q.wake:
+	case <-timeout:
+	}
+	return nil
+}
+
+// attempt makes an attempt at t and updates the Store, returning t as saved
+// or nil if it was removed. The attempt is counted in the Store before it is
+// made, so that one that never completes still counts towards MaxAttempts.
+func (q *Queue) attempt(ctx context.Context, t Task) (*Task, error) {
+	if t.Policy.MaxAttempts > 0 && t.Attempts >= t.Policy.MaxAttempts {
+		return nil, q.dead(t, ErrInterrupted)
+	}
+	t.Attempts++
+	if err := q.store.Put(t); err != nil {
+		return nil, err
+	}
+	err := q.handler(ctx, t)
+	if err == nil {
+		return nil, q.store.Delete(t.ID)
+	}
+	if ctx.Err() != nil {
+		// The attempt was interrupted; it will be repeated by the next Run.
+		t.Attempts--
+		return nil, q.store.Put(t)
+	}
+	if !q.retryable(t, err) {
+		return nil, q.dead(t, err)
+	}
+	delay, ok := retry.RetryAfter(err)
+	if !ok {
+		delay, _ = t.Policy.Delay(t.Attempts)
+	}
+	t.NextRun = retry.CurrentClock().Now().Add(delay)
+	t.LastError = err.Error()
+	return &t, q.store.Put(t)
+}
+
+// dead removes t, which failed with err, from the Store and passes it to the
+// Dead function.
+func (q *Queue) dead(t Task, err error) error {
+	if err := q.store.Delete(t.ID); err != nil {
+		return err
+	}
+	if q.Dead != nil {
+		q.Dead(t, err)
+	}
+	return nil
+}
+
+// retryable reports whether t should be attempted again after failing with
+// err.
+func (q *Queue) retryable(t Task, err error) bool {
+	if retry.IsPermanent(err) {
+		return false
+	}
+	if t.Policy.MaxAttempts > 0 && t.Attempts >= t.Policy.MaxAttempts {
+		return false
+	}
+	if t.Policy.MaxElapsed > 0 && retry.CurrentClock().Now().Sub(t.Enqueued) >= time.Duration(t.Policy.MaxElapsed) {
+		return false
+	}
+	return true
+}
+
+// newID returns a random task ID.
+func newID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
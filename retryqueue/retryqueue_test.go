@@ -0,0 +1,180 @@
+package retryqueue_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/colvin/retry"
+	"github.com/colvin/retry/retryqueue"
+	"github.com/colvin/retry/retrytest"
+)
+
+var errFail = errors.New("fail")
+
+// run runs q until a task is dead-lettered, returning the task and its
+// error.
+func run(t *testing.T, q *retryqueue.Queue) (retryqueue.Task, error) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var task retryqueue.Task
+	var taskErr error
+	q.Dead = func(dead retryqueue.Task, err error) {
+		task, taskErr = dead, err
+		cancel()
+	}
+	if err := q.Run(ctx); err != context.Canceled {
+		t.Fatalf("Run = %v, want %v", err, context.Canceled)
+	}
+	return task, taskErr
+}
+
+func newStore(t *testing.T) *retryqueue.FileStore {
+	t.Helper()
+	s, err := retryqueue.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestQueueDeadLettersOnExhaustion(t *testing.T) {
+	clock := retrytest.NewClock(time.Now())
+	defer clock.Install()()
+
+	store := newStore(t)
+	var attempts int
+	q := retryqueue.New(store, func(_ context.Context, _ retryqueue.Task) error {
+		attempts++
+		return errFail
+	})
+	policy := retry.Config{MaxAttempts: 3, Base: retry.Duration(time.Second), Ceiling: retry.Duration(time.Minute)}
+	id, err := q.Enqueue("webhook", []byte("payload"), policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := run(t, q)
+	if err != errFail {
+		t.Errorf("err = %v, want %v", err, errFail)
+	}
+	if task.ID != id || task.Attempts != 3 || attempts != 3 {
+		t.Errorf("task %s dead after %d attempts (%d made), want %s after 3", task.ID, task.Attempts, attempts, id)
+	}
+	if tasks, _ := store.List(); len(tasks) != 0 {
+		t.Errorf("store holds %d tasks, want 0", len(tasks))
+	}
+}
+
+func TestQueueResumesWithRemainingAttempts(t *testing.T) {
+	clock := retrytest.NewClock(time.Now())
+	defer clock.Install()()
+
+	// A previous process made two of the task's four attempts.
+	store := newStore(t)
+	saved := retryqueue.Task{
+		ID:       "resumed",
+		Kind:     "webhook",
+		Policy:   retry.Config{MaxAttempts: 4, Base: retry.Duration(time.Second)},
+		Attempts: 2,
+		Enqueued: clock.Now(),
+		NextRun:  clock.Now(),
+	}
+	if err := store.Put(saved); err != nil {
+		t.Fatal(err)
+	}
+
+	var counted []int
+	q := retryqueue.New(store, func(_ context.Context, task retryqueue.Task) error {
+		// The attempt is saved before it is made.
+		tasks, err := store.List()
+		if err != nil || len(tasks) != 1 {
+			t.Fatalf("List = %v, %v", tasks, err)
+		}
+		counted = append(counted, tasks[0].Attempts)
+		return errFail
+	})
+	task, err := run(t, q)
+	if err != errFail || task.Attempts != 4 {
+		t.Errorf("task dead after %d attempts with %v, want 4 with %v", task.Attempts, err, errFail)
+	}
+	if want := []int{3, 4}; !reflect.DeepEqual(counted, want) {
+		t.Errorf("saved attempts = %v, want %v", counted, want)
+	}
+}
+
+func TestQueueDeadLettersInterruptedLastAttempt(t *testing.T) {
+	clock := retrytest.NewClock(time.Now())
+	defer clock.Install()()
+
+	// A previous process crashed during the task's last attempt.
+	store := newStore(t)
+	saved := retryqueue.Task{
+		ID:       "crashed",
+		Policy:   retry.Config{MaxAttempts: 2},
+		Attempts: 2,
+		Enqueued: clock.Now(),
+		NextRun:  clock.Now(),
+	}
+	if err := store.Put(saved); err != nil {
+		t.Fatal(err)
+	}
+	q := retryqueue.New(store, func(context.Context, retryqueue.Task) error {
+		t.Error("handler called for a task with no attempts left")
+		return nil
+	})
+	if task, err := run(t, q); task.ID != saved.ID || err != retryqueue.ErrInterrupted {
+		t.Errorf("dead task %q with %v, want %q with %v", task.ID, err, saved.ID, retryqueue.ErrInterrupted)
+	}
+}
+
+func TestFileStorePutIsAtomic(t *testing.T) {
+	dir := t.TempDir()
+	store, err := retryqueue.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task := retryqueue.Task{ID: "a", Kind: "webhook", Attempts: 1}
+	if err := store.Put(task); err != nil {
+		t.Fatal(err)
+	}
+	task.Attempts = 2
+	if err := store.Put(task); err != nil {
+		t.Fatal(err)
+	}
+	// A temporary file left by a crash during Put is not a task.
+	if err := os.WriteFile(filepath.Join(dir, "b.123.tmp"), []byte(`{"id":`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if want := []string{"a.json", "b.123.tmp"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("files = %v, want %v", names, want)
+	}
+	tasks, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != "a" || tasks[0].Attempts != 2 {
+		t.Errorf("List = %+v, want task a with 2 attempts", tasks)
+	}
+
+	if err := store.Delete("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete("a"); err != nil {
+		t.Errorf("Delete of a missing task = %v, want nil", err)
+	}
+}
@@ -0,0 +1,126 @@
+// Package retrysql retries database/sql transactions that fail because of
+// serialization failures or deadlocks.
+package retrysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/colvin/retry"
+)
+
+// DefaultRetryer is the Retryer used by a Runner without one.
+var DefaultRetryer = retry.New(
+	retry.WithMaxAttempts(5),
+	retry.WithTimer(retry.JitteredBackoffPolicy(10*time.Millisecond, time.Second, retry.FullJitter)),
+)
+
+// Classifier reports whether an error means that a transaction should be
+// retried from the beginning.
+type Classifier func(error) bool
+
+// Postgres classifies PostgreSQL serialization failures (SQLSTATE 40001) and
+// deadlocks (40P01) as retryable. It recognizes errors from drivers exposing
+// the SQLSTATE through a SQLState method, as those of pgx and lib/pq do.
+func Postgres(err error) bool {
+	var state interface{ SQLState() string }
+	if !errors.As(err, &state) {
+		return false
+	}
+	switch state.SQLState() {
+	case "40001", "40P01":
+		return true
+	}
+	return false
+}
+
+// MySQL classifies MySQL deadlocks (error 1213) as retryable. The MySQL
+// driver does not expose error numbers through an interface, so the error
+// messages in err's chain are inspected instead.
+func MySQL(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if strings.HasPrefix(err.Error(), "Error 1213") {
+			return true
+		}
+	}
+	return false
+}
+
+// Any returns a Classifier reporting an error as retryable if any of the
+// given Classifiers does.
+func Any(classifiers ...Classifier) Classifier {
+	return func(err error) bool {
+		for _, c := range classifiers {
+			if c(err) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// DefaultClassifier is the Classifier used by a Runner without one.
+var DefaultClassifier = Any(Postgres, MySQL)
+
+// Runner runs transactions in retry loops. Each attempt begins a new
+// transaction, passes it to the function and commits it if the function
+// succeeds or rolls it back if it fails. Errors from any of these steps that
+// are classified as retryable are retried; all others terminate the loop.
+type Runner struct {
+	// Retryer makes the loops. If nil, DefaultRetryer is used.
+	Retryer *retry.Retryer
+	// Classify decides which errors are retried. If nil, DefaultClassifier
+	// is used.
+	Classify Classifier
+	// TxOptions are passed to BeginTx.
+	TxOptions *sql.TxOptions
+}
+
+// Run runs fn in a transaction on db, retrying as described by Runner. The
+// function may be called several times, so it must not have effects outside
+// the transaction that cannot be repeated.
+func (r Runner) Run(ctx context.Context, db *sql.DB, fn func(*sql.Tx) error) error {
+	retryer := r.Retryer
+	if retryer == nil {
+		retryer = DefaultRetryer
+	}
+	classify := r.Classify
+	if classify == nil {
+		classify = DefaultClassifier
+	}
+	return retryer.DoContext(ctx, func(ctx context.Context) error {
+		err := r.attempt(ctx, db, fn)
+		if err != nil && !classify(err) {
+			return retry.Permanent(err)
+		}
+		return err
+	})
+}
+
+// attempt runs fn in a single transaction.
+func (r Runner) attempt(ctx context.Context, db *sql.DB, fn func(*sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, r.TxOptions)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if v := recover(); v != nil {
+			tx.Rollback()
+			panic(v)
+		}
+	}()
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// WithTx runs fn in a transaction on db using a Runner with the given
+// Retryer and the default classification.
+func WithTx(ctx context.Context, db *sql.DB, r *retry.Retryer, fn func(*sql.Tx) error) error {
+	return Runner{Retryer: r}.Run(ctx, db, fn)
+}
@@ -0,0 +1,89 @@
+// Package retrytest provides utilities for testing code that uses package
+// retry.
+package retrytest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/colvin/retry"
+)
+
+// Clock is a fake retry.Clock. Rather than waiting, sleeping on a Clock
+// advances its time by the duration of the sleep and returns immediately, and
+// its timers fire as soon as they are created, likewise advancing its time.
+// Every sleep is recorded. It is safe for concurrent use.
+type Clock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+// NewClock returns a Clock whose time starts at start.
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Install makes c the current retry.Clock and returns a function that
+// restores the previous one, suitable for deferring or passing to
+// testing.T.Cleanup.
+func (c *Clock) Install() (restore func()) {
+	return retry.SetClock(c)
+}
+
+// Now returns the current time of the Clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the time of the Clock forward without recording a sleep. It
+// may be used to simulate time taken by a Worker.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Sleep records a sleep and advances the time of the Clock by d.
+func (c *Clock) Sleep(d time.Duration) {
+	c.advance(d)
+}
+
+// NewTimer records a sleep, advances the time of the Clock by d and returns a
+// timer that has already fired.
+func (c *Clock) NewTimer(d time.Duration) retry.ClockTimer {
+	ch := make(chan time.Time, 1)
+	ch <- c.advance(d)
+	return timer(ch)
+}
+
+// Sleeps returns the duration of every sleep made on the Clock, in order.
+func (c *Clock) Sleeps() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.sleeps...)
+}
+
+func (c *Clock) advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d < 0 {
+		d = 0
+	}
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	return c.now
+}
+
+// timer is a retry.ClockTimer that has already fired.
+type timer chan time.Time
+
+func (t timer) C() <-chan time.Time {
+	return t
+}
+
+func (t timer) Stop() bool {
+	return false
+}
@@ -0,0 +1,59 @@
+package retrytest
+
+import (
+	"time"
+
+	"github.com/colvin/retry"
+)
+
+// Result describes a simulated retry loop.
+type Result struct {
+	// Attempts is the number of attempts made.
+	Attempts int
+	// Delays is the duration of every sleep made between attempts, in order.
+	Delays []time.Duration
+	// Elapsed is the simulated time taken by the loop.
+	Elapsed time.Duration
+	// Err is the error returned by the loop.
+	Err error
+}
+
+// Simulate runs a loop of r without actually sleeping, and reports what the
+// loop did. The Worker of the loop returns each of outcomes in turn, nil
+// meaning success; once they are exhausted the last is repeated, so that a
+// single error simulates a Worker that always fails. With no outcomes the
+// Worker always succeeds.
+//
+// Simulate installs a Clock for the duration of the loop, so it must not be
+// used concurrently with anything else using package retry, including other
+// simulations.
+func Simulate(r *retry.Retryer, outcomes ...error) Result {
+	start := time.Unix(0, 0)
+	clock := NewClock(start)
+	defer clock.Install()()
+
+	var res Result
+	res.Err = r.Do(func() error {
+		res.Attempts++
+		if len(outcomes) == 0 {
+			return nil
+		}
+		if res.Attempts > len(outcomes) {
+			return outcomes[len(outcomes)-1]
+		}
+		return outcomes[res.Attempts-1]
+	})
+	res.Delays = clock.Sleeps()
+	res.Elapsed = clock.Now().Sub(start)
+	return res
+}
+
+// Failures returns n copies of err followed by a nil outcome, for use with
+// Simulate to describe a Worker that succeeds after failing n times.
+func Failures(n int, err error) []error {
+	outcomes := make([]error, n+1)
+	for i := 0; i < n; i++ {
+		outcomes[i] = err
+	}
+	return outcomes
+}
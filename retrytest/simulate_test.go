@@ -0,0 +1,54 @@
+package retrytest_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/colvin/retry"
+	"github.com/colvin/retry/retrytest"
+)
+
+var errFail = errors.New("fail")
+
+func TestSimulateExhausted(t *testing.T) {
+	r := retry.New(retry.WithMaxAttempts(5), retry.WithBackoff(time.Second, time.Minute))
+	res := retrytest.Simulate(r, errFail)
+
+	if res.Attempts != 5 {
+		t.Errorf("attempts = %d, want 5", res.Attempts)
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second}
+	if !reflect.DeepEqual(res.Delays, want) {
+		t.Errorf("delays = %v, want %v", res.Delays, want)
+	}
+	if res.Elapsed != 15*time.Second {
+		t.Errorf("elapsed = %v, want 15s", res.Elapsed)
+	}
+	if res.Err != errFail {
+		t.Errorf("err = %v, want %v", res.Err, errFail)
+	}
+}
+
+func TestSimulateFailures(t *testing.T) {
+	if got, want := retrytest.Failures(2, errFail), []error{errFail, errFail, nil}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Failures(2) = %v, want %v", got, want)
+	}
+
+	r := retry.New(retry.WithMaxAttempts(5), retry.WithBackoff(time.Second, time.Minute))
+	res := retrytest.Simulate(r, retrytest.Failures(2, errFail)...)
+	if res.Attempts != 3 || res.Err != nil {
+		t.Errorf("%d attempts ending with %v, want 3 ending with success", res.Attempts, res.Err)
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second}; !reflect.DeepEqual(res.Delays, want) {
+		t.Errorf("delays = %v, want %v", res.Delays, want)
+	}
+}
+
+func TestSimulateSucceeds(t *testing.T) {
+	res := retrytest.Simulate(retry.New(retry.WithMaxAttempts(5)))
+	if res.Attempts != 1 || res.Err != nil || len(res.Delays) != 0 || res.Elapsed != 0 {
+		t.Errorf("result = %+v, want a single successful attempt", res)
+	}
+}
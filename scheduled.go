@@ -0,0 +1,49 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Scheduled returns a Timer that sleeps until the time returned by next when
+// passed the current time, for integrations that must be retried at
+// particular times rather than after particular delays. Its sleeps can be
+// canceled using a context. If next returns a time that has passed, the Timer
+// returns immediately.
+//
+// next may be any schedule function, such as one returned by Every or Daily
+// or the Next method of a parsed cron expression.
+func Scheduled(ctx context.Context, next func(now time.Time) time.Time) Timer {
+	return cancelableSleeper(ctx, func() time.Duration {
+		return until(next(now()))
+	})
+}
+
+// Every returns a schedule function for Scheduled returning the start of the
+// next interval of length d, intervals being aligned to multiples of d since
+// the zero time. For example, Every(time.Minute) returns the top of the next
+// minute.
+func Every(d time.Duration) func(time.Time) time.Time {
+	return func(now time.Time) time.Time {
+		return now.Truncate(d).Add(d)
+	}
+}
+
+// Daily returns a schedule function for Scheduled returning the next time
+// that the wall clock in loc reads offset past midnight, such as the start of
+// a daily maintenance window. The offset is a time of day rather than elapsed
+// time, so the result does not drift on days when daylight saving time
+// begins or ends.
+func Daily(offset time.Duration, loc *time.Location) func(time.Time) time.Time {
+	hour, minute := int(offset/time.Hour), int(offset/time.Minute%60)
+	sec, nsec := int(offset/time.Second%60), int(offset%time.Second)
+	return func(now time.Time) time.Time {
+		now = now.In(loc)
+		y, m, d := now.Date()
+		t := time.Date(y, m, d, hour, minute, sec, nsec, loc)
+		if !t.After(now) {
+			t = time.Date(y, m, d+1, hour, minute, sec, nsec, loc)
+		}
+		return t
+	}
+}
@@ -0,0 +1,72 @@
+package retry
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestEvery(t *testing.T) {
+	next := Every(time.Minute)
+	tests := []struct {
+		now, want time.Time
+	}{
+		{time.Date(2026, 3, 8, 10, 30, 15, 0, time.UTC), time.Date(2026, 3, 8, 10, 31, 0, 0, time.UTC)},
+		{time.Date(2026, 3, 8, 10, 31, 0, 0, time.UTC), time.Date(2026, 3, 8, 10, 32, 0, 0, time.UTC)},
+		{time.Date(2026, 3, 8, 23, 59, 59, 0, time.UTC), time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := next(tt.now); !got.Equal(tt.want) {
+			t.Errorf("Every(1m)(%v) = %v, want %v", tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestDaily(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := Daily(3*time.Hour+30*time.Minute, ny)
+	tests := []struct {
+		name      string
+		now, want time.Time
+	}{
+		{
+			name: "later today",
+			now:  time.Date(2026, 6, 1, 1, 0, 0, 0, ny),
+			want: time.Date(2026, 6, 1, 3, 30, 0, 0, ny),
+		},
+		{
+			name: "tomorrow",
+			now:  time.Date(2026, 6, 1, 3, 30, 0, 0, ny),
+			want: time.Date(2026, 6, 2, 3, 30, 0, 0, ny),
+		},
+		{
+			name: "daylight saving time begins",
+			now:  time.Date(2026, 3, 8, 1, 0, 0, 0, ny),
+			want: time.Date(2026, 3, 8, 3, 30, 0, 0, ny),
+		},
+		{
+			name: "daylight saving time ends",
+			now:  time.Date(2026, 11, 1, 0, 30, 0, 0, ny),
+			want: time.Date(2026, 11, 1, 3, 30, 0, 0, ny),
+		},
+		{
+			name: "other location",
+			now:  time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC),
+			want: time.Date(2026, 3, 9, 3, 30, 0, 0, ny),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := next(tt.now)
+			if !got.Equal(tt.want) {
+				t.Errorf("Daily(3h30m)(%v) = %v, want %v", tt.now, got, tt.want)
+			}
+			if h, m, _ := got.In(ny).Clock(); h != 3 || m != 30 {
+				t.Errorf("Daily(3h30m)(%v) = %v, want 03:30 local time", tt.now, got.In(ny))
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package retry
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyContext is replaceable so that signal delivery can be simulated.
+var notifyContext = signal.NotifyContext
+
+// ShutdownContext returns a context that is canceled when the process
+// receives its first SIGINT or SIGTERM. It is intended to be shared by retry
+// loops using UntilCanceled or CancelableLimiter so that they stop promptly
+// during a graceful shutdown. The returned function stops listening for the
+// signals and releases the context; it should be called once the context is
+// no longer needed.
+func ShutdownContext() (context.Context, func()) {
+	return notifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
@@ -0,0 +1,49 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestShutdownContextStopsLoop(t *testing.T) {
+	var signal func()
+	defer func(orig func(context.Context, ...os.Signal) (context.Context, context.CancelFunc)) {
+		notifyContext = orig
+	}(notifyContext)
+	notifyContext = func(parent context.Context, _ ...os.Signal) (context.Context, context.CancelFunc) {
+		ctx, cancel := context.WithCancel(parent)
+		signal = cancel
+		return ctx, cancel
+	}
+
+	ctx, stop := ShutdownContext()
+	defer stop()
+
+	errFail := errors.New("fail")
+	var attempts int
+	done := make(chan error, 1)
+	go func() {
+		done <- Retry(func() error {
+			attempts++
+			if attempts == 3 {
+				signal()
+			}
+			return errFail
+		}, UntilCanceled(ctx), CancelableSleep(ctx, time.Millisecond))
+	}()
+
+	select {
+	case err := <-done:
+		if err != errFail {
+			t.Errorf("err = %v, want %v", err, errFail)
+		}
+		if attempts != 3 {
+			t.Errorf("attempts = %d, want 3", attempts)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("loop did not stop after the signal")
+	}
+}
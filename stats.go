@@ -0,0 +1,167 @@
+package retry
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultStatsSize is the number of samples retained by a Stats created with a
+// non-positive size.
+const DefaultStatsSize = 1024
+
+// Stats collects statistics about many retry loops, typically all of those
+// made by a long-lived service. Loops report into it using RetryStats. Memory
+// is bounded by retaining a uniform random sample of observations rather than
+// every one. A Stats must be created with NewStats. It is safe for concurrent
+// use.
+type Stats struct {
+	mu        sync.Mutex
+	loops     int64
+	successes int64
+	attempts  reservoir
+	backoffs  reservoir
+}
+
+// NewStats returns a Stats that retains up to size samples of each
+// distribution it tracks.
+func NewStats(size int) *Stats {
+	if size <= 0 {
+		size = DefaultStatsSize
+	}
+	return &Stats{
+		attempts: reservoir{samples: make([]int64, 0, size)},
+		backoffs: reservoir{samples: make([]int64, 0, size)},
+	}
+}
+
+// StatsSnapshot is a point-in-time summary of a Stats.
+type StatsSnapshot struct {
+	// Loops is the number of loops that have finished.
+	Loops int64
+	// Successes is the number of loops that ended with a successful attempt.
+	Successes int64
+	// GiveUps is the number of loops that ended with a failed attempt.
+	GiveUps int64
+	// GiveUpRate is GiveUps as a fraction of Loops.
+	GiveUpRate float64
+	// Attempts is the distribution of the number of attempts made by
+	// successful loops.
+	Attempts AttemptPercentiles
+	// Backoff is the distribution of the time spent in a Timer between
+	// attempts.
+	Backoff DurationPercentiles
+}
+
+// AttemptPercentiles summarizes a distribution of attempt counts.
+type AttemptPercentiles struct {
+	P50, P90, P99, Max int
+}
+
+// DurationPercentiles summarizes a distribution of durations.
+type DurationPercentiles struct {
+	P50, P90, P99, Max time.Duration
+}
+
+// Snapshot returns a summary of the loops reported so far.
+func (s *Stats) Snapshot() StatsSnapshot {
+	s.mu.Lock()
+	snap := StatsSnapshot{
+		Loops:     s.loops,
+		Successes: s.successes,
+		GiveUps:   s.loops - s.successes,
+	}
+	attempts := s.attempts.sorted()
+	backoffs := s.backoffs.sorted()
+	s.mu.Unlock()
+
+	if snap.Loops > 0 {
+		snap.GiveUpRate = float64(snap.GiveUps) / float64(snap.Loops)
+	}
+	snap.Attempts = AttemptPercentiles{
+		P50: int(percentile(attempts, 0.50)),
+		P90: int(percentile(attempts, 0.90)),
+		P99: int(percentile(attempts, 0.99)),
+		Max: int(percentile(attempts, 1)),
+	}
+	snap.Backoff = DurationPercentiles{
+		P50: time.Duration(percentile(backoffs, 0.50)),
+		P90: time.Duration(percentile(backoffs, 0.90)),
+		P99: time.Duration(percentile(backoffs, 0.99)),
+		Max: time.Duration(percentile(backoffs, 1)),
+	}
+	return snap
+}
+
+func (s *Stats) record(attempts int, backoffs []time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loops++
+	if err == nil {
+		s.successes++
+		s.attempts.add(int64(attempts))
+	}
+	for _, b := range backoffs {
+		s.backoffs.add(int64(b))
+	}
+}
+
+// RetryStats is the same as Retry but reports the outcome of the loop, the
+// number of attempts it made and the time spent in each call to the Timer to
+// stats.
+func RetryStats(stats *Stats, worker Worker, limiter Limiter, timer Timer) error {
+	var attempts int
+	var backoffs []time.Duration
+	err := Retry(func() error {
+		attempts++
+		return worker()
+	}, limiter, func() {
+		start := now()
+		timer()
+		backoffs = append(backoffs, since(start))
+	})
+	stats.record(attempts, backoffs, err)
+	return err
+}
+
+// reservoir retains a uniform random sample of the values added to it using
+// Vitter's Algorithm R. Its capacity is that of its samples slice.
+type reservoir struct {
+	samples []int64
+	seen    int64
+}
+
+func (r *reservoir) add(v int64) {
+	r.seen++
+	if len(r.samples) < cap(r.samples) {
+		r.samples = append(r.samples, v)
+		return
+	}
+	if i := int64(Rand() * float64(r.seen)); i < int64(len(r.samples)) {
+		r.samples[i] = v
+	}
+}
+
+func (r *reservoir) sorted() []int64 {
+	s := make([]int64, len(r.samples))
+	copy(s, r.samples)
+	sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+	return s
+}
+
+// percentile returns the nearest-rank percentile p, in [0,1], of the sorted
+// values, or zero if there are none.
+func percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
@@ -0,0 +1,57 @@
+package retry
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPercentile(t *testing.T) {
+	sorted := []int64{1, 2, 3, 4, 5, 6}
+	tests := []struct {
+		p    float64
+		want int64
+	}{
+		{0, 1},
+		{0.5, 3},
+		{0.9, 6},
+		{1, 6},
+	}
+	for _, tt := range tests {
+		if got := percentile(sorted, tt.p); got != tt.want {
+			t.Errorf("percentile(%v) = %d, want %d", tt.p, got, tt.want)
+		}
+	}
+	if got := percentile(nil, 0.5); got != 0 {
+		t.Errorf("percentile of no values = %d, want 0", got)
+	}
+}
+
+func TestRetryStats(t *testing.T) {
+	stats := NewStats(0)
+	errFail := errors.New("fail")
+	// Loop i succeeds on attempt i%10+1, except every tenth gives up.
+	for i := 0; i < 100; i++ {
+		succeedOn := i%10 + 1
+		var attempts int
+		RetryStats(stats, func() error {
+			attempts++
+			if attempts < succeedOn {
+				return errFail
+			}
+			return nil
+		}, Counts(9), func() {})
+	}
+
+	snap := stats.Snapshot()
+	if snap.Loops != 100 || snap.Successes != 90 || snap.GiveUps != 10 {
+		t.Errorf("loops, successes, give-ups = %d, %d, %d, want 100, 90, 10",
+			snap.Loops, snap.Successes, snap.GiveUps)
+	}
+	if snap.GiveUpRate != 0.1 {
+		t.Errorf("give-up rate = %v, want 0.1", snap.GiveUpRate)
+	}
+	want := AttemptPercentiles{P50: 5, P90: 9, P99: 9, Max: 9}
+	if snap.Attempts != want {
+		t.Errorf("attempts = %+v, want %+v", snap.Attempts, want)
+	}
+}
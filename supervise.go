@@ -0,0 +1,46 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Supervise keeps a long-running Worker, such as a consumer loop or a watch
+// on a stream, running until ctx is done. The Worker is restarted whenever it
+// returns, whether with an error or cleanly, subject to the Limiter, which is
+// passed the Worker's error or nil. The Timer is called before each restart.
+// If a run of the Worker lasted at least healthy, the Limiter and Timer are
+// constructed afresh from their policies first, so that a Worker that fails
+// after running well for a while restarts without the accumulated backoff.
+//
+// Supervise returns the context's error once ctx is done. It also returns if
+// the Limiter refuses a restart, with the Worker's last error, or if the
+// Worker returns a permanent error.
+func Supervise(ctx context.Context, worker ContextWorker, limiter LimiterPolicy, timer TimerPolicy, healthy time.Duration) error {
+	tctx, state := withTimerState(ctx)
+	l, t := limiter(), timer(tctx)
+	for n := 1; ; n++ {
+		start := now()
+		err := worker(withAttempt(ctx, n))
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if perm, ok := unwrapPermanent(err); ok {
+			return perm
+		}
+		if since(start) >= healthy {
+			l, t = limiter(), timer(tctx)
+		}
+		if !l(err) {
+			return err
+		}
+		slept := now()
+		state.begin(err, nil)
+		t()
+		state.remainder(ctx, since(slept))
+		state.end()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrAttemptTimeout is returned by a Worker made by WithTimeout when an
+// attempt exceeds its timeout. It is an ordinary error, so the Limiter decides
+// whether the attempt is retried.
+var ErrAttemptTimeout = errors.New("retry: attempt timed out")
+
+// WithTimeout returns a ContextWorker that bounds each attempt of worker to
+// the given duration. The attempt is passed a context that is canceled after
+// the timeout and, if it has not returned by then, is abandoned so that the
+// loop can continue; an abandoned attempt keeps running in its own goroutine
+// until it returns. An attempt that times out returns ErrAttemptTimeout,
+// unless the loop's own context is done, in which case the context's error
+// is returned.
+func WithTimeout(worker ContextWorker, timeout time.Duration) ContextWorker {
+	return func(ctx context.Context) error {
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		done := make(chan error, 1)
+		go func() {
+			done <- worker(attemptCtx)
+		}()
+
+		var err error
+		select {
+		case err = <-done:
+			if err == nil {
+				return nil
+			}
+		case <-attemptCtx.Done():
+			err = ctx.Err()
+		}
+		if ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+			return ErrAttemptTimeout
+		}
+		return err
+	}
+}
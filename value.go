@@ -0,0 +1,37 @@
+package retry
+
+import "errors"
+
+// ErrNotAccepted is seen by the Limiter, and returned if the loop terminates,
+// when an attempt of RetryUntil succeeds with a value that is not accepted.
+var ErrNotAccepted = errors.New("retry: result not accepted")
+
+// RetryValue is the same as Retry for a Worker that produces a value. The
+// value returned by the successful attempt is returned. If the loop
+// terminates without success the value returned by the final attempt is
+// returned along with its error.
+func RetryValue[T any](worker func() (T, error), limiter Limiter, timer Timer) (T, error) {
+	var v T
+	err := Retry(func() error {
+		var err error
+		v, err = worker()
+		return err
+	}, limiter, timer)
+	return v, err
+}
+
+// RetryUntil is the same as RetryValue but also retries attempts that succeed
+// with a value for which accept returns false, as when polling for a
+// resource that is not ready yet. The Limiter sees such an attempt as having
+// failed with ErrNotAccepted. If the loop terminates without an accepted
+// value, the value returned by the final attempt is returned along with its
+// error, which is ErrNotAccepted if the attempt itself succeeded.
+func RetryUntil[T any](worker func() (T, error), accept func(T) bool, limiter Limiter, timer Timer) (T, error) {
+	return RetryValue(func() (T, error) {
+		v, err := worker()
+		if err == nil && !accept(v) {
+			err = ErrNotAccepted
+		}
+		return v, err
+	}, limiter, timer)
+}